@@ -15,6 +15,11 @@
 package clippy
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/thediveo/clippy/cliplugin"
 	"github.com/thediveo/go-plugger/v3"
@@ -28,10 +33,56 @@ func AddFlags(rootCmd *cobra.Command) {
 	}
 }
 
+// AddFlagsTree runs all registered [cliplugin.SetupCLI] plugin functions in
+// order to register CLI flags for the commands in the command tree starting at
+// the specified root command. SetupCLI plugin functions are run only on those
+// commands matching the command paths returned by a [cliplugin.CommandPaths]
+// symbol exposed by the same plugin. If a plugin doesn't expose any
+// CommandPaths symbol, then its SetupCLI plugin function is run on the root
+// command, as with [AddFlags].
+//
+// AddFlagsTree must be called only after all sub commands have been added to the
+// command tree.
+func AddFlagsTree(rootCmd *cobra.Command) {
+	scopes := plugger.Group[cliplugin.CommandPaths]()
+	for _, plug := range plugger.Group[cliplugin.SetupCLI]().PluginsSymbols() {
+		commandPaths := scopes.PluginSymbol(plug.Plugin)
+		if commandPaths == nil {
+			plug.S(rootCmd)
+			continue
+		}
+		paths := map[string]struct{}{}
+		for _, path := range commandPaths() {
+			paths[strings.Join(strings.Fields(path), " ")] = struct{}{}
+		}
+		walkCommands(rootCmd, "", func(cmd *cobra.Command, path string) {
+			if _, ok := paths[path]; ok {
+				plug.S(cmd)
+			}
+		})
+	}
+}
+
+// walkCommands calls fn for the specified command and all its sub commands,
+// passing the command path relative to the command where the walk started.
+func walkCommands(cmd *cobra.Command, path string, fn func(*cobra.Command, string)) {
+	fn(cmd, path)
+	for _, subCmd := range cmd.Commands() {
+		subPath := subCmd.Name()
+		if path != "" {
+			subPath = path + " " + subPath
+		}
+		walkCommands(subCmd, subPath, fn)
+	}
+}
+
 // BeforeCommand runs all registered [cliplugin.BeforeCommand] plugin functions
 // just before the selected command runs; it terminates as soon as the first
-// plugin function returns a non-nil error.
+// plugin function returns a non-nil error. BeforeCommand attaches a fresh
+// registry for tracking the provenance of flag values to the command, see also
+// [Provenance].
 func BeforeCommand(cmd *cobra.Command) error {
+	startProvenance(cmd)
 	for _, beforeCmd := range plugger.Group[cliplugin.BeforeCommand]().Symbols() {
 		if err := beforeCmd(cmd); err != nil {
 			return err
@@ -39,3 +90,34 @@ func BeforeCommand(cmd *cobra.Command) error {
 	}
 	return nil
 }
+
+// BeforeCommandAll runs all registered [cliplugin.BeforeCommand] plugin
+// functions just before the selected command runs. In contrast to
+// [BeforeCommand] it doesn't stop at the first plugin function returning an
+// error, but instead runs all plugin functions and collects their errors. Each
+// error is annotated with the name of the plugin that returned it. The errors
+// are returned joined together, rendering as one line per error.
+func BeforeCommandAll(cmd *cobra.Command) error {
+	startProvenance(cmd)
+	var errs []error
+	for _, plug := range plugger.Group[cliplugin.BeforeCommand]().PluginsSymbols() {
+		if err := plug.S(cmd); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", plug.Plugin, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AfterCommand runs all registered [cliplugin.AfterCommand] plugin functions
+// after the selected command has run, passing them the error returned by the
+// command (if any). The plugin functions are run in reverse plugin order, so
+// that resources get cleaned up in the opposite order they were set up in the
+// [cliplugin.BeforeCommand] plugin functions. All plugin functions are run
+// regardless of errors, and their errors are returned joined together.
+func AfterCommand(cmd *cobra.Command, runErr error) error {
+	var errs []error
+	for _, afterCmd := range slices.Backward(plugger.Group[cliplugin.AfterCommand]().Symbols()) {
+		errs = append(errs, afterCmd(cmd, runErr))
+	}
+	return errors.Join(errs...)
+}
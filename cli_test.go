@@ -29,6 +29,7 @@ import (
 func init() {
 	plugger.Group[cliplugin.SetupCLI]().Register(canarySetupCLI)
 	plugger.Group[cliplugin.BeforeCommand]().Register(canaryBeforeRun)
+	plugger.Group[cliplugin.AfterCommand]().Register(canaryAfterRun)
 }
 
 type canaryVariable int
@@ -37,6 +38,7 @@ const (
 	setupCLICountRef canaryVariable = iota
 	beforeRunRef
 	beforeRunErr
+	afterRunRef
 )
 
 func canarySetupCLI(cmd *cobra.Command) {
@@ -51,6 +53,15 @@ func canaryBeforeRun(cmd *cobra.Command) error {
 	return beforeRunErr
 }
 
+func canaryAfterRun(cmd *cobra.Command, runErr error) error {
+	afterRun, ok := cmd.Context().Value(afterRunRef).(*[]error)
+	if !ok {
+		return nil
+	}
+	*afterRun = append(*afterRun, runErr)
+	return nil
+}
+
 var _ = Describe("clippy", func() {
 
 	It("calls AddFlags plugin method", func() {
@@ -65,6 +76,47 @@ var _ = Describe("clippy", func() {
 		Expect(count).To(Equal(1))
 	})
 
+	It("scopes SetupCLI plugin methods to sub commands", func() {
+		group := plugger.Group[cliplugin.SetupCLI]()
+		stash := group.Backup()
+		DeferCleanup(func() { group.Restore(stash) })
+		group.Clear()
+		scopes := plugger.Group[cliplugin.CommandPaths]()
+		scopesStash := scopes.Backup()
+		DeferCleanup(func() { scopes.Restore(scopesStash) })
+		scopes.Clear()
+
+		group.Register(func(cmd *cobra.Command) {
+			cmd.PersistentFlags().Bool("global", false, "")
+		}, plugger.WithPlugin("global"))
+		group.Register(func(cmd *cobra.Command) {
+			cmd.Flags().Int("port", 0, "")
+		}, plugger.WithPlugin("server"))
+		scopes.Register(func() []string { return []string{"serve"} },
+			plugger.WithPlugin("server"))
+		group.Register(func(cmd *cobra.Command) {
+			cmd.Flags().Bool("dry-run", false, "")
+		}, plugger.WithPlugin("migrate"))
+		scopes.Register(func() []string { return []string{" db  migrate", "foo"} },
+			plugger.WithPlugin("migrate"))
+
+		rootCmd := &cobra.Command{Use: "root"}
+		serveCmd := &cobra.Command{Use: "serve"}
+		versionCmd := &cobra.Command{Use: "version"}
+		dbCmd := &cobra.Command{Use: "db"}
+		migrateCmd := &cobra.Command{Use: "migrate"}
+		dbCmd.AddCommand(migrateCmd)
+		rootCmd.AddCommand(serveCmd, versionCmd, dbCmd)
+
+		AddFlagsTree(rootCmd)
+		Expect(rootCmd.PersistentFlags().Lookup("global")).NotTo(BeNil())
+		Expect(rootCmd.Flags().Lookup("port")).To(BeNil())
+		Expect(serveCmd.Flags().Lookup("port")).NotTo(BeNil())
+		Expect(versionCmd.Flags().Lookup("port")).To(BeNil())
+		Expect(dbCmd.Flags().Lookup("dry-run")).To(BeNil())
+		Expect(migrateCmd.Flags().Lookup("dry-run")).NotTo(BeNil())
+	})
+
 	It("calls BeforeCommand plugin method", func() {
 		var count int
 		ctx := context.WithValue(context.Background(),
@@ -86,4 +138,64 @@ var _ = Describe("clippy", func() {
 		Expect(count).To(Equal(2))
 	})
 
+	It("calls all BeforeCommand plugin methods and collects their errors", func() {
+		group := plugger.Group[cliplugin.BeforeCommand]()
+		stash := group.Backup()
+		DeferCleanup(func() { group.Restore(stash) })
+		group.Clear()
+
+		var order []string
+		for _, name := range []string{"a", "b", "c"} {
+			group.Register(func(*cobra.Command) error {
+				order = append(order, name)
+				if name == "b" {
+					return nil
+				}
+				return errors.New(name + " failed")
+			}, plugger.WithPlugin(name))
+		}
+
+		err := BeforeCommandAll(&cobra.Command{})
+		Expect(err).To(MatchError("a: a failed\nc: c failed"))
+		Expect(order).To(HaveExactElements("a", "b", "c"))
+
+		group.Clear()
+		Expect(BeforeCommandAll(&cobra.Command{})).To(Succeed())
+	})
+
+	It("calls AfterCommand plugin method", func() {
+		var runErrs []error
+		ctx := context.WithValue(context.Background(),
+			afterRunRef, &runErrs)
+
+		rootCmd := &cobra.Command{}
+		rootCmd.SetContext(ctx)
+		Expect(AfterCommand(rootCmd, nil)).To(Succeed())
+		runErr := errors.New("fooerror")
+		Expect(AfterCommand(rootCmd, runErr)).To(Succeed())
+		Expect(runErrs).To(HaveExactElements(BeNil(), BeIdenticalTo(runErr)))
+	})
+
+	It("calls AfterCommand plugin methods in reverse order and joins their errors", func() {
+		group := plugger.Group[cliplugin.AfterCommand]()
+		stash := group.Backup()
+		DeferCleanup(func() { group.Restore(stash) })
+		group.Clear()
+
+		var order []string
+		for _, name := range []string{"a", "b", "c"} {
+			group.Register(func(*cobra.Command, error) error {
+				order = append(order, name)
+				if name == "b" {
+					return nil
+				}
+				return errors.New(name + " failed")
+			}, plugger.WithPlugin(name))
+		}
+
+		err := AfterCommand(&cobra.Command{}, nil)
+		Expect(err).To(MatchError("c failed\na failed"))
+		Expect(order).To(HaveExactElements("c", "b", "a"))
+	})
+
 })
@@ -20,6 +20,21 @@ import "github.com/spf13/cobra"
 // root command.
 type SetupCLI func(*cobra.Command)
 
+// CommandPaths defines an exposed plugin symbol type for restricting the
+// [SetupCLI] symbol exposed by the same (named) plugin to only the commands with
+// the returned command paths. A command path is the sequence of (sub) command
+// names below the root command, separated by spaces, such as "db migrate". The
+// empty command path "" denotes the root command itself.
+//
+// CommandPaths symbols are only taken into account by clippy.AddFlagsTree.
+type CommandPaths func() []string
+
 // BeforeCommand defines an exposed plugin symbol type for running checks after
 // the command line args have been processed and before running the command.
 type BeforeCommand func(*cobra.Command) error
+
+// AfterCommand defines an exposed plugin symbol type for cleaning up after the
+// command has run, such as closing resources opened in a BeforeCommand plugin.
+// The error returned from running the command is passed in, or nil if the
+// command succeeded.
+type AfterCommand func(cmd *cobra.Command, runErr error) error
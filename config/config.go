@@ -0,0 +1,331 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/thediveo/clippy"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/go-plugger/v3"
+	"go.yaml.in/yaml/v3"
+)
+
+// Names of the CLI flags defined and used in this package.
+const (
+	ConfigFlagName = "config"
+)
+
+// Names of the configuration files looked for in the search path, in order.
+var configFileNames = []string{"config.yaml", "config.yml", "config.json"}
+
+// Register our plugin functions. Our beforeCommand plugin wants to run before
+// any other plugin, so that all other plugins see the flag values taken from
+// the configuration file, with the notable exception of the “clippy/env”
+// plugin: as the env plugin also asks to be placed at the beginning but gets
+// placed after us, it runs before us so that environment variables take
+// precedence over the configuration file.
+func init() {
+	plugger.Group[cliplugin.SetupCLI]().Register(
+		setupCLI, plugger.WithPlugin("clippy/config"))
+	plugger.Group[cliplugin.BeforeCommand]().Register(
+		beforeCommand, plugger.WithPlugin("clippy/config"), plugger.WithPlacement("<"))
+}
+
+// setupCLI adds the "--config" flag to the specified command.
+func setupCLI(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(ConfigFlagName, "", "configuration file (YAML or JSON)")
+}
+
+// ctxKey "namespaces" the context keys this package uses internally for passing
+// API user configuration(s) via contexts attached to cobra commands.
+type ctxKey int
+
+const (
+	ctxSearchPath ctxKey = iota
+)
+
+// SetSearchPath allows API users to override the default search path for
+// configuration files.
+func SetSearchPath(cmd *cobra.Command, dirs ...string) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.SetContext(context.WithValue(ctx, ctxSearchPath, dirs))
+}
+
+// searchPath returns the list of directories to search for a configuration
+// file.
+func searchPath(cmd *cobra.Command) []string {
+	if dirs, ok := cmd.Context().Value(ctxSearchPath).([]string); ok {
+		return dirs
+	}
+	app := cmd.Root().Name()
+	var dirs []string
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, app))
+	}
+	return append(dirs, filepath.Join("/etc", app))
+}
+
+// beforeCommand loads the configuration file either specified using “--config”
+// or found in the search path, and then sets all flags that haven't been set
+// yet.
+func beforeCommand(cmd *cobra.Command) error {
+	filename, _ := cmd.Flags().GetString(ConfigFlagName)
+	if filename == "" {
+		filename = find(searchPath(cmd))
+		if filename == "" {
+			return nil
+		}
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("cannot read configuration, %w", err)
+	}
+	var doc *yaml.Node
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		doc, err = parseJSON(data)
+	} else {
+		doc = &yaml.Node{}
+		err = yaml.Unmarshal(data, doc)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration file %s, %w", filename, err)
+	}
+	return apply(cmd, filename, doc)
+}
+
+// find returns the name of the first configuration file found in the specified
+// directories, or "" if none could be found.
+func find(dirs []string) string {
+	for _, dir := range dirs {
+		for _, name := range configFileNames {
+			filename := filepath.Join(dir, name)
+			if info, err := os.Stat(filename); err == nil && info.Mode().IsRegular() {
+				return filename
+			}
+		}
+	}
+	return ""
+}
+
+// applier sets flags of the command about to run from the values of a
+// configuration file, collecting errors on the way.
+type applier struct {
+	cmd      *cobra.Command
+	filename string
+	values   map[*pflag.Flag]flagValue
+	order    []*pflag.Flag
+	errs     []error
+}
+
+// flagValue is a flag value from a configuration file, together with the
+// nesting depth of the command the value was specified for.
+type flagValue struct {
+	node  *yaml.Node
+	depth int
+}
+
+// apply the configuration in the specified document node to the flags of the
+// specified command about to run. Flags that have already been set, or that are
+// mutually exclusive with flags already set, are left alone.
+func apply(cmd *cobra.Command, filename string, doc *yaml.Node) error {
+	if doc.Kind == yaml.DocumentNode {
+		doc = doc.Content[0]
+	}
+	if doc.Kind == 0 {
+		return nil // empty configuration file
+	}
+	a := &applier{
+		cmd:      cmd,
+		filename: filename,
+		values:   map[*pflag.Flag]flagValue{},
+	}
+	a.walk(cmd.Root(), doc)
+	for _, flag := range a.order {
+		if flag.Changed || clippy.MutuallyExcluded(a.cmd, flag.Name, clippy.SourceConfig) {
+			continue
+		}
+		a.set(flag, a.values[flag].node)
+	}
+	return errors.Join(a.errs...)
+}
+
+// errorf records an error at the position of the specified node.
+func (a *applier) errorf(node *yaml.Node, format string, args ...any) {
+	a.errs = append(a.errs, fmt.Errorf("%s:%d:%d: %s",
+		a.filename, node.Line, node.Column, fmt.Sprintf(format, args...)))
+}
+
+// walk the keys of the specified mapping node, which either are flag names of
+// the specified command or names of its sub commands. Flag values are only
+// recorded if the specified command is on the path to the command about to
+// run.
+func (a *applier) walk(cmd *cobra.Command, node *yaml.Node) {
+	node = resolve(node)
+	if node.Kind != yaml.MappingNode {
+		a.errorf(node, "expected flag names and sub commands for command %q",
+			cmd.CommandPath())
+		return
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		key, value := node.Content[idx], resolve(node.Content[idx+1])
+		if flag := lookupFlag(cmd, key.Value); flag != nil {
+			a.record(cmd, flag, value)
+			continue
+		}
+		if subCmd := lookupCommand(cmd, key.Value); subCmd != nil {
+			a.walk(subCmd, value)
+			continue
+		}
+		a.errorf(key, "unknown flag or sub command %q for command %q",
+			key.Value, cmd.CommandPath())
+	}
+}
+
+// record the value for the specified flag of the specified command, but only if
+// the command is on the path to the command about to run, and if the flag
+// applies to the command about to run. Values for more deeply nested commands
+// take precedence over values for their parent commands, regardless of their
+// order in the configuration file; otherwise, the last value wins.
+func (a *applier) record(cmd *cobra.Command, flag *pflag.Flag, value *yaml.Node) {
+	switch value.Kind {
+	case yaml.ScalarNode:
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			if resolve(item).Kind != yaml.ScalarNode {
+				a.errorf(item, "expected value for flag %q", flag.Name)
+				return
+			}
+		}
+	default:
+		a.errorf(value, "expected value for flag %q", flag.Name)
+		return
+	}
+	if !isAncestor(cmd, a.cmd) || a.cmd.Flags().Lookup(flag.Name) != flag {
+		return
+	}
+	depth := depth(cmd)
+	recorded, ok := a.values[flag]
+	if !ok {
+		a.order = append(a.order, flag)
+	} else if recorded.depth > depth {
+		return
+	}
+	a.values[flag] = flagValue{node: value, depth: depth}
+}
+
+// set the specified flag to the specified value, recording the position of the
+// value as the flag value's origin.
+func (a *applier) set(flag *pflag.Flag, value *yaml.Node) {
+	if value.Kind == yaml.ScalarNode {
+		if err := a.cmd.Flags().Set(flag.Name, value.Value); err != nil {
+			a.errorf(value, "invalid value %q for flag %q, %s", value.Value, flag.Name, err)
+			return
+		}
+		a.setSource(flag, value)
+		return
+	}
+	values := make([]string, 0, len(value.Content))
+	for _, item := range value.Content {
+		values = append(values, resolve(item).Value)
+	}
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		if err := sliceValue.Replace(values); err != nil {
+			a.errorf(value, "invalid values for flag %q, %s", flag.Name, err)
+			return
+		}
+		flag.Changed = true
+		a.setSource(flag, value)
+		return
+	}
+	if len(values) != 1 {
+		a.errorf(value, "expected single value for flag %q", flag.Name)
+		return
+	}
+	if err := a.cmd.Flags().Set(flag.Name, values[0]); err != nil {
+		a.errorf(value, "invalid value %q for flag %q, %s", values[0], flag.Name, err)
+		return
+	}
+	a.setSource(flag, value)
+}
+
+// setSource records the configuration file position of the specified value as
+// the origin of the flag's value.
+func (a *applier) setSource(flag *pflag.Flag, value *yaml.Node) {
+	clippy.SetSource(a.cmd, flag.Name, clippy.SourceConfig,
+		fmt.Sprintf("%s:%d:%d", a.filename, value.Line, value.Column))
+}
+
+// resolve aliases to the nodes they refer to.
+func resolve(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+// lookupFlag returns the named flag applicable to the specified command, or nil
+// if the command has no such flag.
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	if flag := cmd.Flags().Lookup(name); flag != nil {
+		return flag
+	}
+	if flag := cmd.PersistentFlags().Lookup(name); flag != nil {
+		return flag
+	}
+	return cmd.InheritedFlags().Lookup(name)
+}
+
+// lookupCommand returns the named sub command of the specified command, or nil
+// if there is no such sub command.
+func lookupCommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, subCmd := range cmd.Commands() {
+		if subCmd.Name() == name {
+			return subCmd
+		}
+	}
+	return nil
+}
+
+// depth returns the nesting depth of the specified command, with the root
+// command having depth 0.
+func depth(cmd *cobra.Command) int {
+	depth := 0
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		depth++
+	}
+	return depth
+}
+
+// isAncestor returns true if the specified ancestor command is either the
+// specified command itself or one of its parents.
+func isAncestor(ancestor *cobra.Command, cmd *cobra.Command) bool {
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd == ancestor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func writeConfig(dir, name, content string) string {
+	GinkgoHelper()
+	filename := filepath.Join(dir, name)
+	Expect(os.WriteFile(filename, []byte(content), 0o600)).To(Succeed())
+	return filename
+}
+
+var _ = Describe("configuration files", func() {
+
+	var rootCmd, serveCmd, dbCmd, migrateCmd *cobra.Command
+	var dir string
+
+	var debug bool
+	var tags []string
+	var timeout time.Duration
+	var port int
+	var dryRun bool
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+
+		rootCmd = &cobra.Command{
+			Use: "my-app",
+			PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+				return clippy.BeforeCommand(cmd)
+			},
+			RunE:          func(*cobra.Command, []string) error { return nil },
+			SilenceErrors: true,
+			SilenceUsage:  true,
+		}
+		rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "")
+		rootCmd.PersistentFlags().StringSliceVar(&tags, "tags", nil, "")
+		rootCmd.Flags().DurationVar(&timeout, "wait-timeout", time.Second, "")
+		serveCmd = &cobra.Command{
+			Use:  "serve",
+			RunE: func(*cobra.Command, []string) error { return nil },
+		}
+		serveCmd.Flags().IntVar(&port, "port", 42, "")
+		dbCmd = &cobra.Command{Use: "db"}
+		migrateCmd = &cobra.Command{
+			Use:  "migrate",
+			RunE: func(*cobra.Command, []string) error { return nil },
+		}
+		migrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "")
+		dbCmd.AddCommand(migrateCmd)
+		rootCmd.AddCommand(serveCmd, dbCmd)
+		SetSearchPath(rootCmd, dir)
+		clippy.AddFlags(rootCmd)
+	})
+
+	It("defaults to searching the user's and system configuration directories", func() {
+		cmd := &cobra.Command{Use: "foo"}
+		cmd.SetContext(GinkgoT().Context())
+		Expect(searchPath(cmd)).To(ContainElement("/etc/foo"))
+	})
+
+	It("runs without any configuration file", func() {
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeFalse())
+	})
+
+	It("reports a missing configuration file", func() {
+		rootCmd.SetArgs([]string{"--" + ConfigFlagName, filepath.Join(dir, "missing.yaml")})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring("cannot read configuration")))
+	})
+
+	It("reports invalid configuration files", func() {
+		writeConfig(dir, "config.yaml", "debug: [")
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring("invalid configuration file")))
+	})
+
+	It("ignores empty configuration files", func() {
+		writeConfig(dir, "config.yaml", "")
+		Expect(rootCmd.Execute()).To(Succeed())
+	})
+
+	It("sets flags from a YAML configuration file in the search path", func() {
+		writeConfig(dir, "config.yaml", `debug: true
+tags: [foo, bar]
+wait-timeout: 1m
+serve:
+  port: 666
+`)
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeTrue())
+		Expect(tags).To(ConsistOf("foo", "bar"))
+		Expect(timeout).To(Equal(time.Minute))
+		Expect(port).To(Equal(42))
+	})
+
+	It("sets flags of sub commands", func() {
+		filename := writeConfig(dir, "foo.yaml", `debug: true
+tags: foo,bar
+wait-timeout: 1m
+serve:
+  port: 666
+db:
+  tags: [baz]
+  migrate:
+    dry-run: true
+`)
+		rootCmd.SetArgs([]string{"db", "migrate", "--" + ConfigFlagName, filename})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeTrue())
+		Expect(tags).To(ConsistOf("baz"))
+		Expect(timeout).To(Equal(time.Second))
+		Expect(port).To(Equal(42))
+		Expect(dryRun).To(BeTrue())
+	})
+
+	It("gives flags of sub commands precedence regardless of their order", func() {
+		filename := writeConfig(dir, "foo.yaml", `db:
+  migrate:
+    debug: false
+  tags: [foo]
+debug: true
+tags: [bar]
+serve:
+  debug: true
+`)
+		debug = true
+		rootCmd.SetArgs([]string{"db", "migrate", "--" + ConfigFlagName, filename})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeFalse())
+		Expect(tags).To(ConsistOf("foo"))
+	})
+
+	It("gives command line flags precedence", func() {
+		writeConfig(dir, "config.yml", `tags: [foo, bar]
+serve:
+  port: 666
+`)
+		rootCmd.SetArgs([]string{"serve", "--tags", "baz", "--port", "1"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(tags).To(ConsistOf("baz"))
+		Expect(port).To(Equal(1))
+	})
+
+	It("gives mutually exclusive command line flags precedence", func() {
+		logLevel := rootCmd.PersistentFlags().String("log-level", "info", "")
+		rootCmd.MarkFlagsMutuallyExclusive("debug", "log-level")
+		writeConfig(dir, "config.yaml", `log-level: warn
+wait-timeout: 1m
+`)
+		rootCmd.SetArgs([]string{"--debug"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeTrue())
+		Expect(*logLevel).To(Equal("info"))
+		Expect(timeout).To(Equal(time.Minute))
+	})
+
+	It("reports unknown keys and invalid values with their positions", func() {
+		filename := writeConfig(dir, "config.yaml", `debug: dunno
+foo: bar
+serve:
+  port: [1, 2]
+  bar: 42
+db: 42
+`)
+		err := rootCmd.Execute()
+		Expect(err).To(MatchError(ContainSubstring(filename + `:1:8: invalid value "dunno" for flag "debug"`)))
+		Expect(err).To(MatchError(ContainSubstring(filename + `:2:1: unknown flag or sub command "foo" for command "my-app"`)))
+		Expect(err).To(MatchError(ContainSubstring(filename + `:5:3: unknown flag or sub command "bar" for command "my-app serve"`)))
+		Expect(err).To(MatchError(ContainSubstring(filename + `:6:5: expected flag names and sub commands for command "my-app db"`)))
+		Expect(err).NotTo(MatchError(ContainSubstring(`expected single value`)))
+
+		rootCmd.SetArgs([]string{"serve"})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring(filename + `:4:9: expected single value for flag "port"`)))
+	})
+
+	It("sets flags from a JSON configuration file", func() {
+		writeConfig(dir, "config.json", `{
+	"debug": true,
+	"tags": ["foo", "bar"],
+	"serve": {"port": 666}
+}`)
+		rootCmd.SetArgs([]string{"serve"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeTrue())
+		Expect(tags).To(ConsistOf("foo", "bar"))
+		Expect(port).To(Equal(666))
+	})
+
+	It("reports unknown keys in JSON configuration files with their positions", func() {
+		filename := writeConfig(dir, "config.json", `{
+	"debug": true,
+	"serve": {
+		"port": {"foo": 1},
+		"bar": null
+	}
+}`)
+		rootCmd.SetArgs([]string{"serve"})
+		err := rootCmd.Execute()
+		Expect(err).To(MatchError(ContainSubstring(filename + `:4:11: expected value for flag "port"`)))
+		Expect(err).To(MatchError(ContainSubstring(filename + `:5:3: unknown flag or sub command "bar"`)))
+	})
+
+})
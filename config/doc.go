@@ -0,0 +1,35 @@
+/*
+Package config supplies the “--config” CLI flag for setting flags from a YAML
+or JSON configuration file. Flags explicitly specified on the command line (or
+set by other means, such as environment variables, before this package's
+[cliplugin.BeforeCommand] plugin function runs) take precedence over the
+configuration file. This includes flags mutually exclusive with flags already
+set: their values in the configuration file are then ignored.
+
+The keys in the configuration file mirror the flag names. Flags of sub commands
+are nested inside a key named after the sub command, such as:
+
+	debug: true
+	serve:
+	  port: 8080
+	db:
+	  migrate:
+	    dry-run: true
+
+The values of slice flags can be specified either as sequences or as
+comma-separated lists of values. Unknown keys are reported as errors, including
+their positions in the configuration file.
+
+JSON configuration files are detected by their “.json” file extension; all
+other configuration files are considered to be YAML.
+
+When “--config” isn't specified, the search path is scanned for a file named
+“config.yaml”, “config.yml”, or “config.json”. The search path defaults to
+“$XDG_CONFIG_HOME/<app>/” and “/etc/<app>/”, with <app> being the name of the
+root command. Use [SetSearchPath] to override the default search path.
+
+Flag values set from a configuration file record their positions in the file
+as their origins; the [github.com/thediveo/clippy/config/printconfig] package
+prints the effective configuration including these origins.
+*/
+package config
@@ -0,0 +1,118 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// jsonParser parses JSON into a tree of YAML nodes, so that JSON configuration
+// files can be processed the same way as YAML configuration files, including
+// the positions of nodes.
+type jsonParser struct {
+	data []byte
+	dec  *json.Decoder
+}
+
+// parseJSON parses the specified JSON data, returning the corresponding tree
+// of YAML nodes.
+func parseJSON(data []byte) (*yaml.Node, error) {
+	p := &jsonParser{
+		data: data,
+		dec:  json.NewDecoder(bytes.NewReader(data)),
+	}
+	p.dec.UseNumber()
+	node, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	line, col := p.pos()
+	if _, err := p.dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("line %d:%d: unexpected trailing data", line, col)
+	}
+	return node, nil
+}
+
+// pos returns the line and column of the next token.
+func (p *jsonParser) pos() (line, col int) {
+	offset := int(p.dec.InputOffset())
+	for offset < len(p.data) && bytes.IndexByte([]byte(" \t\r\n,:"), p.data[offset]) >= 0 {
+		offset++
+	}
+	lineStart := bytes.LastIndexByte(p.data[:offset], '\n') + 1
+	return bytes.Count(p.data[:offset], []byte{'\n'}) + 1, offset - lineStart + 1
+}
+
+// value parses the next JSON value.
+func (p *jsonParser) value() (*yaml.Node, error) {
+	line, col := p.pos()
+	tok, err := p.dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("line %d:%d: %w", line, col, err)
+	}
+	node := &yaml.Node{Line: line, Column: col}
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			for p.dec.More() {
+				key, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, key, value)
+			}
+		case '[':
+			node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			for p.dec.More() {
+				item, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+		}
+		// consume the closing delimiter; the decoder already checks for
+		// properly matching delimiters.
+		line, col := p.pos()
+		if _, err := p.dec.Token(); err != nil {
+			return nil, fmt.Errorf("line %d:%d: %w", line, col, err)
+		}
+	case string:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!str", tok
+	case json.Number:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!float", tok.String()
+		if _, err := tok.Int64(); err == nil {
+			node.Tag = "!!int"
+		}
+	case bool:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", strconv.FormatBool(tok)
+	case nil:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+	}
+	return node, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package config
+
+import (
+	"go.yaml.in/yaml/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+var _ = Describe("JSON configuration", func() {
+
+	It("parses JSON into YAML nodes with positions", func() {
+		node, err := parseJSON([]byte(`{"a": [1, 2.5, "x"],
+  "b": true, "c": null}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(node.Kind).To(Equal(yaml.MappingNode))
+		Expect(node.Content).To(HaveLen(6))
+		Expect(node.Content[0]).To(PointTo(MatchFields(IgnoreExtras, Fields{
+			"Kind": Equal(yaml.ScalarNode), "Value": Equal("a"), "Line": Equal(1), "Column": Equal(2),
+		})))
+		Expect(node.Content[1].Content).To(HaveExactElements(
+			PointTo(MatchFields(IgnoreExtras, Fields{"Tag": Equal("!!int"), "Value": Equal("1"), "Column": Equal(8)})),
+			PointTo(MatchFields(IgnoreExtras, Fields{"Tag": Equal("!!float"), "Value": Equal("2.5")})),
+			PointTo(MatchFields(IgnoreExtras, Fields{"Tag": Equal("!!str"), "Value": Equal("x")})),
+		))
+		Expect(node.Content[2]).To(PointTo(MatchFields(IgnoreExtras, Fields{
+			"Value": Equal("b"), "Line": Equal(2), "Column": Equal(3),
+		})))
+		Expect(node.Content[3].Value).To(Equal("true"))
+		Expect(node.Content[5].Tag).To(Equal("!!null"))
+	})
+
+	DescribeTable("rejects invalid JSON",
+		func(data string, msg string) {
+			_, err := parseJSON([]byte(data))
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+		},
+		Entry("empty", ``, "line 1:1: EOF"),
+		Entry("unterminated object", `{"a": 1`, "line 1:8: "),
+		Entry("unterminated array", `[1`, "line 1:3: "),
+		Entry("missing value", `{"a": }`, "line 1:7: "),
+		Entry("trailing data", `{} {}`, "line 1:4: unexpected trailing data"),
+	)
+
+})
@@ -0,0 +1,27 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+/*
+Package printconfig supplies the hidden “--print-config” CLI flag for printing
+the effective configuration of the command, either as a table or in JSON
+format, instead of running the command. The effective configuration lists all
+flags with their values and where these values came from, such as the command
+line, environment variables, or a configuration file. Please see also
+[clippy.Provenance].
+
+This package can be used independently of the
+[github.com/thediveo/clippy/config] package, such as when only binding flags
+to environment variables.
+*/
+package printconfig
@@ -0,0 +1,94 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package printconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/go-plugger/v3"
+)
+
+// Names of the CLI flags defined and used in this package.
+const (
+	PrintConfigFlagName = "print-config"
+)
+
+// Formats for printing the effective configuration.
+const (
+	PrintTable = "table"
+	PrintJSON  = "json"
+)
+
+// Register our plugin functions. Our beforeCommand plugin wants to run last so
+// that it sees the effective configuration after all other plugins have had
+// their say.
+func init() {
+	plugger.Group[cliplugin.SetupCLI]().Register(
+		setupCLI, plugger.WithPlugin("clippy/config/printconfig"))
+	plugger.Group[cliplugin.BeforeCommand]().Register(
+		beforeCommand, plugger.WithPlugin("clippy/config/printconfig"), plugger.WithPlacement(">"))
+}
+
+// setupCLI adds the hidden "--print-config" flag to the specified command.
+func setupCLI(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(PrintConfigFlagName, "",
+		"prints the effective configuration as \""+PrintTable+"\" or \""+PrintJSON+
+			"\" instead of running the command")
+	cmd.PersistentFlags().Lookup(PrintConfigFlagName).NoOptDefVal = PrintTable
+	_ = cmd.PersistentFlags().MarkHidden(PrintConfigFlagName)
+}
+
+// beforeCommand prints the effective configuration of the command about to
+// run, if requested, and then replaces the command's run function so that the
+// command doesn't actually run.
+func beforeCommand(cmd *cobra.Command) error {
+	format, _ := cmd.Flags().GetString(PrintConfigFlagName)
+	if format == "" {
+		return nil
+	}
+	flags := slices.DeleteFunc(clippy.Provenance(cmd), func(fp clippy.FlagProvenance) bool {
+		return fp.Name == PrintConfigFlagName
+	})
+	out := cmd.OutOrStdout()
+	switch format {
+	case PrintTable:
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "FLAG\tVALUE\tSOURCE\tORIGIN")
+		for _, fp := range flags {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", fp.Name, fp.Value, fp.Source, fp.Origin)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	case PrintJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(flags); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid --%s format %q, must be %q or %q",
+			PrintConfigFlagName, format, PrintTable, PrintJSON)
+	}
+	cmd.Run = nil
+	cmd.RunE = func(*cobra.Command, []string) error { return nil }
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package printconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+	"github.com/thediveo/clippy/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("printing the effective configuration", func() {
+
+	var rootCmd *cobra.Command
+	var out bytes.Buffer
+	var ran bool
+
+	BeforeEach(func() {
+		out.Reset()
+		ran = false
+		rootCmd = &cobra.Command{
+			Use: "my-app",
+			PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+				return clippy.BeforeCommand(cmd)
+			},
+			RunE: func(*cobra.Command, []string) error {
+				ran = true
+				return nil
+			},
+			SilenceErrors: true,
+			SilenceUsage:  true,
+		}
+		rootCmd.SetOut(&out)
+		rootCmd.Flags().Int("port", 42, "")
+		rootCmd.Flags().Bool("debug", false, "")
+		config.SetSearchPath(rootCmd, GinkgoT().TempDir())
+		clippy.AddFlags(rootCmd)
+	})
+
+	It("hides the flag", func() {
+		Expect(rootCmd.PersistentFlags().Lookup(PrintConfigFlagName).Hidden).To(BeTrue())
+	})
+
+	It("runs the command when not asked to print", func() {
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(ran).To(BeTrue())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("prints a table instead of running the command", func() {
+		filename := filepath.Join(GinkgoT().TempDir(), "my.yaml")
+		Expect(os.WriteFile(filename, []byte("port: 666\n"), 0o600)).To(Succeed())
+		rootCmd.SetArgs([]string{"--debug", "--" + PrintConfigFlagName, "--" + config.ConfigFlagName, filename})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(ran).To(BeFalse())
+		Expect(out.String()).To(And(
+			MatchRegexp(`FLAG\s+VALUE\s+SOURCE\s+ORIGIN\n`),
+			MatchRegexp(`\nconfig\s+\S+/my.yaml\s+command line\s+\n`),
+			MatchRegexp(`\ndebug\s+true\s+command line\s+\n`),
+			MatchRegexp(`\nport\s+666\s+configuration\s+\S+/my.yaml:1:7\n`),
+		))
+		Expect(out.String()).NotTo(ContainSubstring(PrintConfigFlagName))
+	})
+
+	It("prints JSON", func() {
+		rootCmd.SetArgs([]string{"--" + PrintConfigFlagName + "=" + PrintJSON})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(ran).To(BeFalse())
+		var flags []clippy.FlagProvenance
+		Expect(json.Unmarshal(out.Bytes(), &flags)).To(Succeed())
+		Expect(flags).To(ContainElement(clippy.FlagProvenance{
+			Name: "port", Value: "42", Source: clippy.SourceDefault,
+		}))
+	})
+
+	It("rejects invalid formats", func() {
+		rootCmd.SetArgs([]string{"--" + PrintConfigFlagName + "=foo"})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring(`invalid --print-config format "foo"`)))
+		Expect(ran).To(BeFalse())
+	})
+
+})
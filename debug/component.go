@@ -0,0 +1,215 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ComponentKey is the attribute key identifying the component a log record
+// belongs to.
+const ComponentKey = "component"
+
+// AnyComponent is the wildcard component name in "--log-levels" for all
+// components not explicitly listed.
+const AnyComponent = "*"
+
+// Logger returns a logger for the specified component. The records logged via
+// the returned logger carry the component as their [ComponentKey] attribute and
+// are filtered by the component's level as set using the "--log-levels" flag.
+// Components without an explicit level use the level of the [AnyComponent]
+// wildcard, if present, and otherwise the general log level.
+//
+// Logger can be safely called before the default logger has been installed,
+// such as when initializing package-level variables, as the returned logger
+// always logs via the current default logger.
+func Logger(component string) *slog.Logger {
+	return slog.New(&componentHandler{component: component, mu: &sync.Mutex{}})
+}
+
+// componentLevels maps component names to their log levels. It also is a flag
+// value accepting comma-separated lists of component=level elements.
+type componentLevels map[string]slog.Level
+
+func (c *componentLevels) String() string {
+	elements := make([]string, 0, len(*c))
+	for _, component := range slices.Sorted(maps.Keys(*c)) {
+		elements = append(elements, component+"="+(*c)[component].String())
+	}
+	return strings.Join(elements, ",")
+}
+
+func (c *componentLevels) Set(s string) error {
+	levels := componentLevels{}
+	for element := range strings.SplitSeq(s, ",") {
+		if strings.TrimSpace(element) == "" {
+			continue
+		}
+		component, levelName, ok := strings.Cut(element, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return fmt.Errorf("invalid component level %q, must be component=level", element)
+		}
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			return err
+		}
+		levels[component] = level
+	}
+	*c = levels
+	return nil
+}
+
+func (c *componentLevels) Type() string { return "levels" }
+
+// levelFilter is a [slog.Handler] in front of the handler doing the actual
+// output work, deciding which records to pass on based on the general log
+// level, and on the levels of the components loggers belong to.
+type levelFilter struct {
+	next    slog.Handler
+	level   slog.Leveler    // effective level of this handler.
+	general *slog.LevelVar  // general log level.
+	levels  componentLevels // per-component log levels.
+	grouped bool            // true if attributes are now inside a group.
+}
+
+var _ slog.Handler = (*levelFilter)(nil)
+
+// newLevelFilter returns a new level filtering handler in front of the
+// specified handler, which itself must not filter any levels.
+func newLevelFilter(next slog.Handler, general *slog.LevelVar, levels componentLevels) *levelFilter {
+	return &levelFilter{
+		next:    next,
+		level:   general,
+		general: general,
+		levels:  levels,
+	}
+}
+
+func (h *levelFilter) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *levelFilter) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a new handler with the specified attributes added. If the
+// attributes include a top-level component attribute, then the new handler
+// uses the level of this component.
+func (h *levelFilter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	if !h.grouped {
+		for _, attr := range attrs {
+			if attr.Key == ComponentKey {
+				h2.level = h.componentLevel(attr.Value.String())
+			}
+		}
+	}
+	return &h2
+}
+
+func (h *levelFilter) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.grouped = true
+	return &h2
+}
+
+// componentLevel returns the level for the specified component.
+func (h *levelFilter) componentLevel(component string) slog.Leveler {
+	if level, ok := h.levels[component]; ok {
+		return level
+	}
+	if level, ok := h.levels[AnyComponent]; ok {
+		return level
+	}
+	return h.general
+}
+
+// componentHandler is a [slog.Handler] logging via the current default logger,
+// adding the component attribute.
+type componentHandler struct {
+	component string
+	ops       []func(slog.Handler) slog.Handler // WithAttrs and WithGroup operations.
+
+	mu      *sync.Mutex
+	base    slog.Handler // default handler derived from.
+	derived slog.Handler // handler derived from base.
+}
+
+var _ slog.Handler = (*componentHandler)(nil)
+
+// handler returns the handler derived from the current default handler,
+// deriving and caching it as necessary.
+func (h *componentHandler) handler() slog.Handler {
+	base := slog.Default().Handler()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.base != nil && reflect.TypeOf(base).Comparable() && base == h.base {
+		return h.derived
+	}
+	h.base, h.derived = base, h.derive(base)
+	return h.derived
+}
+
+// derive a new handler from the specified base handler by adding the
+// component attribute and applying the WithAttrs and WithGroup operations.
+func (h *componentHandler) derive(base slog.Handler) slog.Handler {
+	derived := base.WithAttrs([]slog.Attr{slog.String(ComponentKey, h.component)})
+	for _, op := range h.ops {
+		derived = op(derived)
+	}
+	return derived
+}
+
+func (h *componentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler().Enabled(ctx, level)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler().Handle(ctx, r)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+// with returns a new component handler with the specified operation added.
+func (h *componentHandler) with(op func(slog.Handler) slog.Handler) *componentHandler {
+	return &componentHandler{
+		component: h.component,
+		ops:       append(slices.Clip(h.ops), op),
+		mu:        &sync.Mutex{},
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("component logging", func() {
+
+	var rootCmd *cobra.Command
+	var output bytes.Buffer
+
+	BeforeEach(func() {
+		output.Reset()
+
+		rootCmd = newLoggingRootCmd(&output)
+	})
+
+	It("parses component levels", func() {
+		var levels componentLevels
+		Expect(levels.Set("work=debug, http = warn,,*=info")).To(Succeed())
+		Expect(levels).To(Equal(componentLevels{
+			"work": slog.LevelDebug,
+			"http": slog.LevelWarn,
+			"*":    slog.LevelInfo,
+		}))
+		Expect(levels.String()).To(Equal("*=INFO,http=WARN,work=DEBUG"))
+		Expect(levels.Type()).To(Equal("levels"))
+
+		Expect(levels.Set("work")).To(MatchError(ContainSubstring("must be component=level")))
+		Expect(levels.Set("=debug")).To(MatchError(ContainSubstring("must be component=level")))
+		Expect(levels.Set("work=foo")).To(MatchError(ContainSubstring("invalid log level")))
+	})
+
+	It("filters component records by their component levels", func() {
+		workLog := Logger("work")
+		httpLog := Logger("http")
+		otherLog := Logger("other")
+
+		rootCmd.SetArgs([]string{"--" + LogLevelsFlagName, "work=debug,http=error"})
+		Expect(rootCmd.Execute()).To(Succeed())
+
+		workLog.Debug("*work*")
+		httpLog.Warn("*http*")
+		otherLog.Debug("*other-debug*")
+		otherLog.Info("*other-info*")
+		slog.Debug("*debug*")
+		Expect(output.String()).To(MatchRegexp(`level=DEBUG msg=\*work\* component=work\n.*level=INFO msg=\*other-info\* component=other\n$`))
+	})
+
+	It("applies the wildcard level to unlisted components only", func() {
+		rootCmd.SetArgs([]string{"--" + LogLevelsFlagName, "*=warn,work=debug"})
+		Expect(rootCmd.Execute()).To(Succeed())
+
+		Logger("other").Info("*other*")
+		Logger("work").Debug("*work*")
+		slog.Info("*info*")
+		Expect(output.String()).NotTo(ContainSubstring("*other*"))
+		Expect(output.String()).To(ContainSubstring("*work*"))
+		Expect(output.String()).To(ContainSubstring("*info*"))
+	})
+
+	It("ignores component attributes inside groups", func() {
+		rootCmd.SetArgs([]string{"--" + LogLevelsFlagName, "work=debug"})
+		Expect(rootCmd.Execute()).To(Succeed())
+
+		slog.Default().WithGroup("foo").With(ComponentKey, "work").Debug("*grouped*")
+		Expect(output.String()).To(BeEmpty())
+		slog.Default().WithGroup("").With(ComponentKey, "work").Debug("*ungrouped*")
+		Expect(output.String()).To(ContainSubstring("*ungrouped*"))
+	})
+
+	It("supports attributes and groups on component loggers", func() {
+		Expect(rootCmd.Execute()).To(Succeed())
+
+		log := Logger("work").With("foo", "bar").WithGroup("").WithGroup("baz")
+		log.Info("*work*", "answer", 42)
+		Expect(output.String()).To(MatchRegexp(`msg=\*work\* component=work foo=bar baz.answer=42`))
+	})
+
+	It("follows changes of the default logger", func() {
+		log := Logger("work")
+		Expect(rootCmd.Execute()).To(Succeed())
+		log.Info("*first*")
+		Expect(output.String()).To(ContainSubstring("*first*"))
+
+		var other bytes.Buffer
+		slog.SetDefault(slog.New(slog.NewTextHandler(&other, nil)))
+		log.Info("*second*")
+		Expect(output.String()).NotTo(ContainSubstring("*second*"))
+		Expect(other.String()).To(MatchRegexp(`msg=\*second\* component=work`))
+	})
+
+})
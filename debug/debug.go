@@ -17,9 +17,14 @@ package debug
 import (
 	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/lmittmann/tint"
 
@@ -30,10 +35,55 @@ import (
 
 // Names of the CLI flags defined and used in this package.
 const (
-	DebugFlagName  = "debug"
-	TintedFlagName = "tinted"
+	DebugFlagName     = "debug"
+	TintedFlagName    = "tinted"
+	LogFormatFlagName = "log-format"
+	LogLevelFlagName  = "log-level"
+	LogLevelsFlagName = "log-levels"
+	LogSourceFlagName = "log-source"
+
+	LogFileFlagName           = "log-file"
+	LogFileMaxSizeFlagName    = "log-file-max-size"
+	LogFileMaxBackupsFlagName = "log-file-max-backups"
+	LogFileCompressFlagName   = "log-file-compress"
+
+	LogLevelSignalsFlagName = "log-level-signals"
+	LogControlFlagName      = "log-control"
+
+	LogTargetFlagName     = "log-target"
+	LogTargetAddrFlagName = "log-target-addr"
+
+	LogRateFlagName        = "log-rate"
+	LogSampleAfterFlagName = "log-sample-after"
+)
+
+// Format of the structured logging output.
+type Format string
+
+// The supported structured logging output formats.
+const (
+	FormatText Format = "text" // logfmt-like key=value text, see [slog.TextHandler].
+	FormatJSON Format = "json" // line-delimited JSON, see [slog.JSONHandler].
+	FormatTint Format = "tint" // tinted text, see [tint.NewHandler].
 )
 
+// formatValue is a flag value only accepting the supported structured
+// logging output formats.
+type formatValue Format
+
+func (f *formatValue) String() string { return string(*f) }
+
+func (f *formatValue) Set(s string) error {
+	switch format := Format(s); format {
+	case FormatText, FormatJSON, FormatTint:
+		*f = formatValue(format)
+		return nil
+	}
+	return fmt.Errorf("must be %q, %q, or %q", FormatText, FormatJSON, FormatTint)
+}
+
+func (f *formatValue) Type() string { return "format" }
+
 // Register our plugin functions for delayed registration of CLI flags we bring
 // into the game and the things to check or carry out before the selected
 // command is finally run.
@@ -42,13 +92,62 @@ func init() {
 		setupCLI, plugger.WithPlugin("clippy/debug"))
 	plugger.Group[cliplugin.BeforeCommand]().Register(
 		beforeCommand, plugger.WithPlugin("clippy/debug"))
+	plugger.Group[cliplugin.AfterCommand]().Register(
+		afterCommand, plugger.WithPlugin("clippy/debug"))
 }
 
-// setupCLI adds the "--debug" and "--tinted" flags to the specified command that
-// changes the logging level to debug or enable logging at the info level.
+// setupCLI adds the "--debug", "--log-level", "--tinted", and "--log-format"
+// flags to the specified command that change the logging level and select the
+// logging output format. "--debug" is a shorthand for "--log-level=debug" and
+// thus mutually exclusive with "--log-level". Similarly, "--tinted" is an alias
+// for "--log-format=tint" and thus mutually exclusive with "--log-format".
 func setupCLI(cmd *cobra.Command) {
 	cmd.PersistentFlags().Bool(DebugFlagName, false, "enables debug structured logging output")
+	level := levelValue(slog.LevelInfo)
+	cmd.PersistentFlags().Var(&level, LogLevelFlagName,
+		"structured logging level trace, debug, info, warn, error, or an integer")
+	cmd.MarkFlagsMutuallyExclusive(DebugFlagName, LogLevelFlagName)
+	cmd.PersistentFlags().Var(&componentLevels{}, LogLevelsFlagName,
+		"structured logging levels of components, such as \"work=debug,http=warn,*=info\"")
 	cmd.PersistentFlags().Bool(TintedFlagName, false, "tints structured logging output")
+	format := formatValue(FormatText)
+	cmd.PersistentFlags().Var(&format, LogFormatFlagName,
+		fmt.Sprintf("structured logging output format %q, %q, or %q", FormatText, FormatJSON, FormatTint))
+	cmd.MarkFlagsMutuallyExclusive(TintedFlagName, LogFormatFlagName)
+	source := sourceValue(SourceNone)
+	cmd.PersistentFlags().Var(&source, LogSourceFlagName,
+		fmt.Sprintf("adds source code locations to structured logging output, %q, %q, or %q (relative to module)",
+			SourceNone, SourceFull, SourceRelative))
+	cmd.PersistentFlags().Lookup(LogSourceFlagName).NoOptDefVal = string(SourceFull)
+
+	cmd.PersistentFlags().String(LogFileFlagName, "",
+		"writes structured logging output to the specified file instead of stderr; reopens the file on SIGHUP")
+	cmd.PersistentFlags().Int64(LogFileMaxSizeFlagName, 0,
+		"rotates the log file when reaching the specified size in MiB; 0 disables rotation")
+	cmd.PersistentFlags().Int(LogFileMaxBackupsFlagName, 0,
+		"maximum number of rotated log files to keep; 0 keeps all")
+	cmd.PersistentFlags().Bool(LogFileCompressFlagName, false,
+		"compresses rotated log files using gzip")
+
+	target := targetValue(TargetStderr)
+	cmd.PersistentFlags().Var(&target, LogTargetFlagName,
+		fmt.Sprintf("structured logging output target %q, %q, or %q", TargetStderr, TargetSyslog, TargetJournald))
+	cmd.PersistentFlags().String(LogTargetAddrFlagName, "",
+		"address of the syslog or journald target, either unix:path or (syslog only) a UDP host:port; defaults to "+
+			DefaultSyslogAddr+" and "+DefaultJournaldAddr+" respectively")
+	cmd.MarkFlagsMutuallyExclusive(LogFileFlagName, LogTargetFlagName)
+
+	cmd.PersistentFlags().Var(&rateValue{}, LogRateFlagName,
+		"limits records with the same message and level to a rate, such as \"100/s\"; errors are never dropped")
+	cmd.PersistentFlags().Int(LogSampleAfterFlagName, 0,
+		"after N records with the same message and level per interval, passes only every N-th record; "+
+			"errors are never dropped")
+
+	cmd.PersistentFlags().Bool(LogLevelSignalsFlagName, false,
+		"makes logging more verbose on SIGUSR1 and less verbose on SIGUSR2")
+	cmd.PersistentFlags().String(LogControlFlagName, "",
+		"serves an HTTP endpoint "+LevelControlPath+
+			" for getting and setting the log level at the specified host:port or unix:path")
 }
 
 // ctxKey "namespaces" the context keys this package uses internally for passing
@@ -59,10 +158,15 @@ const (
 	ctxDefaultLevel ctxKey = iota
 	ctxLevel
 	ctxIoWriter
+	ctxFormat
+	ctxResources
+	ctxLevelVar
+	ctxSourceMode
 )
 
 // SetDefaultLevel allows API users to override the default log level (info)
-// with their own default level.
+// with their own default level; the "--debug" and "--log-level" flags take
+// precedence.
 func SetDefaultLevel(cmd *cobra.Command, level slog.Level) {
 	ctx := cmd.Context()
 	if ctx == nil {
@@ -71,8 +175,8 @@ func SetDefaultLevel(cmd *cobra.Command, level slog.Level) {
 	cmd.SetContext(context.WithValue(ctx, ctxDefaultLevel, level))
 }
 
-// SetLevel allows API users to force a specific log level; the "--debug" flag
-// will then be ignored.
+// SetLevel allows API users to force a specific log level; the "--debug" and
+// "--log-level" flags will then be ignored.
 func SetLevel(cmd *cobra.Command, level slog.Level) {
 	ctx := cmd.Context()
 	if ctx == nil {
@@ -81,6 +185,8 @@ func SetLevel(cmd *cobra.Command, level slog.Level) {
 	cmd.SetContext(context.WithValue(ctx, ctxLevel, level))
 }
 
+// SetWriter allows API users to direct the logging output to the specified
+// writer, instead of os.Stderr; the "--log-file" flag takes precedence.
 func SetWriter(cmd *cobra.Command, w io.Writer) {
 	ctx := cmd.Context()
 	if ctx == nil {
@@ -89,32 +195,192 @@ func SetWriter(cmd *cobra.Command, w io.Writer) {
 	cmd.SetContext(context.WithValue(ctx, ctxIoWriter, w))
 }
 
+// SetFormat allows API users to override the default logging output format
+// (text) with their own default format; the "--log-format" and "--tinted" flags
+// take precedence.
+func SetFormat(cmd *cobra.Command, format Format) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.SetContext(context.WithValue(ctx, ctxFormat, format))
+}
+
+// chainReplacers returns a ReplaceAttr function calling the specified
+// replacers in sequence, or nil if there are no replacers.
+func chainReplacers(replacers []func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	if len(replacers) == 0 {
+		return nil
+	}
+	return func(groups []string, attr slog.Attr) slog.Attr {
+		for _, replace := range replacers {
+			attr = replace(groups, attr)
+		}
+		return attr
+	}
+}
+
+// passAll is the log level of output handlers passing all records.
+const passAll = slog.Level(math.MinInt)
+
+// resources acquired by beforeCommand that need to be released by
+// afterCommand, such as log files.
+type resources struct {
+	logger  *slog.Logger   // default logger before running the command.
+	closers []func() error // in order of acquisition.
+}
+
 // beforeCommand enables debug logging (and tinting) before any command finally
 // is executed.
 func beforeCommand(cmd *cobra.Command) error {
+	res := &resources{logger: slog.Default()}
+	cmd.SetContext(context.WithValue(cmd.Context(), ctxResources, res))
+
+	w, _ := cmd.Context().Value(ctxIoWriter).(io.Writer)
+	w = cmp.Or[io.Writer](w, os.Stderr)
+	if filename, _ := cmd.Flags().GetString(LogFileFlagName); filename != "" {
+		maxSize, _ := cmd.Flags().GetInt64(LogFileMaxSizeFlagName)
+		maxBackups, _ := cmd.Flags().GetInt(LogFileMaxBackupsFlagName)
+		compress, _ := cmd.Flags().GetBool(LogFileCompressFlagName)
+		logfile, err := openRotatingFile(filename, maxSize<<20, maxBackups, compress)
+		if err != nil {
+			return fmt.Errorf("cannot open log file, %w", err)
+		}
+		stop := reopenOnHangup(logfile)
+		res.closers = append(res.closers, func() error {
+			stop()
+			return logfile.Close()
+		})
+		w = logfile
+	}
+
 	level := slog.LevelInfo
 	if ctxForcedLevel, ok := cmd.Context().Value(ctxLevel).(slog.Level); ok {
 		level = ctxForcedLevel
+	} else if debug, _ := cmd.Flags().GetBool(DebugFlagName); debug {
+		level = slog.LevelDebug
+	} else if flag := cmd.Flags().Lookup(LogLevelFlagName); flag != nil && flag.Changed {
+		level = slog.Level(*flag.Value.(*levelValue))
 	} else if ctxNewDefaultLevel, ok := cmd.Context().Value(ctxDefaultLevel).(slog.Level); ok {
 		level = ctxNewDefaultLevel
 	}
-	w, _ := cmd.Context().Value(ctxIoWriter).(io.Writer)
-	w = cmp.Or[io.Writer](w, os.Stderr)
 
-	if debug, _ := cmd.PersistentFlags().GetBool(DebugFlagName); debug {
-		level = slog.LevelDebug
+	format, _ := cmd.Context().Value(ctxFormat).(Format)
+	format = cmp.Or(format, FormatText)
+	if tinted, _ := cmd.Flags().GetBool(TintedFlagName); tinted {
+		format = FormatTint
+	} else if flag := cmd.Flags().Lookup(LogFormatFlagName); flag != nil && flag.Changed {
+		format = Format(flag.Value.String())
 	}
-	var handler slog.Handler
-	if tinted, _ := cmd.PersistentFlags().GetBool(TintedFlagName); tinted {
-		handler = tint.NewHandler(w, &tint.Options{
-			Level: level,
-		})
-	} else {
-		handler = slog.NewTextHandler(w, &slog.HandlerOptions{
-			Level: level,
+
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(level)
+	cmd.SetContext(context.WithValue(cmd.Context(), ctxLevelVar, levelVar))
+
+	var levels componentLevels
+	if flag := cmd.Flags().Lookup(LogLevelsFlagName); flag != nil {
+		levels = *flag.Value.(*componentLevels)
+	}
+
+	source, _ := cmd.Context().Value(ctxSourceMode).(SourceMode)
+	source = cmp.Or(source, SourceNone)
+	if flag := cmd.Flags().Lookup(LogSourceFlagName); flag != nil && flag.Changed {
+		source = SourceMode(flag.Value.String())
+	}
+	var replacers []func([]string, slog.Attr) slog.Attr
+	if source == SourceRelative {
+		replacers = append(replacers, relativeSource)
+	}
+	opts := &slog.HandlerOptions{
+		AddSource:   source == SourceFull || source == SourceRelative,
+		Level:       passAll,
+		ReplaceAttr: chainReplacers(replacers),
+	}
+
+	// The output handler passes all records, as filtering by level is done
+	// by the level filter in front of it, taking component levels into
+	// account. The syslog and journald targets bring their own output
+	// handlers, ignoring the output format.
+	handler, conn, err := newTargetHandler(cmd, opts)
+	if err != nil {
+		return err
+	}
+	if conn != nil {
+		res.closers = append(res.closers, conn.Close)
+	}
+	if handler == nil {
+		switch format {
+		case FormatTint:
+			handler = tint.NewHandler(w, &tint.Options{
+				AddSource:   opts.AddSource,
+				Level:       opts.Level,
+				ReplaceAttr: opts.ReplaceAttr,
+			})
+		case FormatJSON:
+			handler = slog.NewJSONHandler(w, opts)
+		case FormatText:
+			handler = slog.NewTextHandler(w, opts)
+		default:
+			return fmt.Errorf("invalid logging output format %q", format)
+		}
+	}
+	if hasRedactions() {
+		handler = newRedactor(handler)
+	}
+	var rate Rate
+	if flag := cmd.Flags().Lookup(LogRateFlagName); flag != nil {
+		rate = Rate(*flag.Value.(*rateValue))
+	}
+	after, _ := cmd.Flags().GetInt(LogSampleAfterFlagName)
+	if after < 0 {
+		return fmt.Errorf("invalid number of records %d before sampling, must not be negative", after)
+	}
+	if rate.N > 0 || after > 0 {
+		sampler := newSampler(handler, rate.N, after)
+		stop := sampler.rollEvery(cmp.Or(rate.Per, time.Second))
+		res.closers = append(res.closers, func() error {
+			stop()
+			return nil
 		})
+		handler = sampler
 	}
+	handler = newLevelFilter(handler, levelVar, levels)
 	slog.SetDefault(slog.New(handler))
 	slog.Debug("debug logging enabled")
+
+	if signals, _ := cmd.Flags().GetBool(LogLevelSignalsFlagName); signals {
+		stop, err := stepLevelOnSignals(levelVar)
+		if err != nil {
+			return err
+		}
+		res.closers = append(res.closers, func() error {
+			stop()
+			return nil
+		})
+	}
+	if addr, _ := cmd.Flags().GetString(LogControlFlagName); addr != "" {
+		stop, err := serveLevelControl(addr, levelVar)
+		if err != nil {
+			return fmt.Errorf("cannot serve log level control, %w", err)
+		}
+		res.closers = append(res.closers, stop)
+	}
 	return nil
 }
+
+// afterCommand releases the resources acquired by beforeCommand, such as log
+// files. In case there were any resources to release, the default logger
+// in use before running the command gets restored.
+func afterCommand(cmd *cobra.Command, _ error) error {
+	res, ok := cmd.Context().Value(ctxResources).(*resources)
+	if !ok || len(res.closers) == 0 {
+		return nil
+	}
+	slog.SetDefault(res.logger)
+	var errs []error
+	for _, closer := range slices.Backward(res.closers) {
+		errs = append(errs, closer())
+	}
+	res.closers = nil
+	return errors.Join(errs...)
+}
@@ -16,7 +16,10 @@ package debug
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/thediveo/clippy"
@@ -63,6 +66,42 @@ var _ = Describe("debug and tint logging", func() {
 		Expect(output.String()).To(MatchRegexp(`(?s)msg="debug logging enabled".*level=DEBUG msg=\*debug\*`))
 	})
 
+	It("logs at the specified level", func() {
+		rootCmd.SetArgs([]string{"foo", "--" + LogLevelFlagName, "trace"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Log(context.Background(), LevelTrace, "*trace*")
+		Expect(output.String()).To(MatchRegexp(`level=DEBUG-4 msg=\*trace\*`))
+
+		output.Reset()
+		rootCmd.SetArgs([]string{"foo", "--" + LogLevelFlagName, "warn"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("*info*")
+		Expect(output.String()).To(BeEmpty())
+		slog.Warn("*warn*")
+		Expect(output.String()).To(MatchRegexp(`level=WARN msg=\*warn\*`))
+	})
+
+	It("gives the log level flag precedence over the default, but not over a forced level", func() {
+		SetDefaultLevel(rootCmd, slog.LevelError)
+		rootCmd.SetArgs([]string{"foo", "--" + LogLevelFlagName, "warn"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Warn("*warn*")
+		Expect(output.String()).To(MatchRegexp(`level=WARN msg=\*warn\*`))
+
+		output.Reset()
+		SetLevel(rootCmd, slog.LevelError)
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Warn("*warn*")
+		Expect(output.String()).To(BeEmpty())
+	})
+
+	It("rejects debug in combination with a log level", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{"--" + DebugFlagName, "--" + LogLevelFlagName + "=info"})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring("none of the others can be")))
+	})
+
 	It("changes the default", func() {
 		rootCmd.SetArgs([]string{"foo"})
 		SetDefaultLevel(rootCmd, slog.LevelDebug)
@@ -88,6 +127,75 @@ var _ = Describe("debug and tint logging", func() {
 		Expect(output.String()).To(MatchRegexp(ansiBrightGreen + "INF" + ansiReset + " hellorld!"))
 	})
 
+	It("logs JSON", func() {
+		rootCmd.SetArgs([]string{"foo", "--" + LogFormatFlagName, string(FormatJSON)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(MatchRegexp(`{"time":".*","level":"INFO","msg":"hellorld!"}`))
+	})
+
+	It("tints using the log format flag", func() {
+		rootCmd.SetArgs([]string{"foo", "--" + LogFormatFlagName + "=" + string(FormatTint)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(ContainSubstring("\u001b["))
+	})
+
+	It("changes the default format", func() {
+		SetFormat(rootCmd, FormatJSON)
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(MatchRegexp(`"msg":"hellorld!"`))
+
+		output.Reset()
+		rootCmd.SetArgs([]string{"--" + LogFormatFlagName + "=" + string(FormatText)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(MatchRegexp(`level=INFO msg=hellorld!`))
+	})
+
+	It("rejects invalid formats", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{"--" + LogFormatFlagName + "=foo"})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring(`must be "text", "json", or "tint"`)))
+
+		SetFormat(rootCmd, Format("foo"))
+		rootCmd.SetArgs([]string{})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring(`invalid logging output format "foo"`)))
+	})
+
+	It("rejects tinted in combination with a log format", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{"--" + TintedFlagName, "--" + LogFormatFlagName + "=json"})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring("none of the others can be")))
+	})
+
+	It("logs to a file and closes it after the command", func() {
+		name := filepath.Join(GinkgoT().TempDir(), "log", "foo.log")
+		rootCmd.SetArgs([]string{"--" + LogFileFlagName, name})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).NotTo(HaveOccurred())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(BeEmpty())
+		Expect(os.ReadFile(name)).To(MatchRegexp(`level=INFO msg=hellorld!`))
+
+		logger := slog.Default()
+		Expect(clippy.AfterCommand(cmd, nil)).To(Succeed())
+		Expect(slog.Default()).NotTo(BeIdenticalTo(logger))
+		Expect(clippy.AfterCommand(cmd, nil)).To(Succeed())
+	})
+
+	It("reports when it cannot open the log file", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{"--" + LogFileFlagName, GinkgoT().TempDir()})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).To(MatchError(ContainSubstring("cannot open log file")))
+		Expect(clippy.AfterCommand(cmd, err)).To(Succeed())
+	})
+
 	Context("created when necessary", func() {
 
 		It("SetDefaultLevel", func() {
@@ -102,6 +210,12 @@ var _ = Describe("debug and tint logging", func() {
 			Expect(cmd.Context()).NotTo(BeNil())
 		})
 
+		It("SetFormat", func() {
+			cmd := &cobra.Command{}
+			SetFormat(cmd, FormatJSON)
+			Expect(cmd.Context()).NotTo(BeNil())
+		})
+
 	})
 
 })
@@ -1,10 +1,70 @@
 /*
-Package debug supplies the “--debug” and “--tinted” CLI flags for configuring
-the default structured logger.
+Package debug supplies CLI flags for configuring the default structured
+logger: its log levels (“--debug”, “--log-level”, and “--log-levels”), runtime
+log level control (“--log-level-signals” and “--log-control”), output format
+(“--log-format”, “--tinted”, and “--log-source”), output destination
+(“--log-file”, “--log-file-max-size”, “--log-file-max-backups”,
+“--log-file-compress”, “--log-target”, and “--log-target-addr”), and output
+rate (“--log-rate” and “--log-sample-after”).
 
   - “--debug” enables structured logging to stderr from debug level on and
-    upwards (so no tracing).
+    upwards (so no tracing). It is a shorthand for “--log-level=debug”.
+  - “--log-level” sets the logging level, either by name “trace”, “debug”,
+    “info”, “warn”, “error”, or as an integer.
   - “--tinted” enables tinted logs using the [lmittmann/tint] module.
+  - “--log-format” selects the logging output format, either “text” (the
+    default), “json”, or “tint”. “--tinted” is a shorthand for
+    “--log-format=tint”.
+  - “--log-source” adds source code locations to the logging output, either
+    with full file paths (“--log-source” or “--log-source=full”), or with file
+    paths relative to the module root (“--log-source=relative”). Relative
+    paths are derived from the module information embedded in the binary, so
+    they work on hosts without the source tree.
+  - “--log-file” writes the logging output to the specified file instead of
+    stderr, creating the file and its parent directories if necessary. The log
+    file gets reopened when receiving SIGHUP, for compatibility with logrotate.
+    “--log-file-max-size” optionally enables size-based rotation, keeping
+    “--log-file-max-backups” rotated files, which are compressed with
+    “--log-file-compress”.
+  - “--log-target” sends the logging output to “stderr” (the default, or the
+    log file), “syslog”, or “journald” instead. The syslog target sends RFC
+    5424 messages to the unix datagram socket /dev/log, with the attributes of
+    records becoming structured data. The journald target uses the native
+    systemd journal protocol, with the attributes of records becoming
+    upper-cased journal fields prefixed with “SLOG_”. “--log-target-addr”
+    overrides the target's address, either a unix socket path prefixed with
+    “unix:”, or a UDP host:port address (syslog only). The output format is
+    ignored for these targets, and “--log-target” is mutually exclusive with
+    “--log-file”.
+  - “--log-levels” sets the logging levels of individual components, such as
+    “work=debug,http=warn,*=info”, with “*” applying to all components not
+    explicitly listed. Components log via loggers obtained from [Logger].
+  - “--log-rate” limits the rate of records with the same message and level,
+    such as “100/s”, “10/m”, or “5/250ms”. “--log-sample-after” passes only
+    every N-th record with the same message and level after the first N ones
+    in an interval (of one second, unless specified by “--log-rate”). Records
+    at error level are never dropped. At the end of each interval, a summary
+    of “N records suppressed” gets logged for each suppressed message.
+  - “--log-level-signals” makes logging more verbose when receiving SIGUSR1,
+    and less verbose when receiving SIGUSR2.
+  - “--log-control” serves an HTTP endpoint for getting (GET) and setting (PUT
+    or POST) the log level at runtime, either at a TCP host:port address, or at
+    a unix socket path prefixed with “unix:”.
+
+The log level can also be changed programmatically at runtime using
+[ChangeLevel], which logs the change, same as the signals and the log level
+control endpoint do. Alternatively, the [slog.LevelVar] returned by [LevelVar]
+can be changed directly, but without logging the change.
+
+Sensitive information can be masked in the logging output by registering
+sensitive attribute keys using [RedactKeys], as well as patterns for sensitive
+parts of string values using [RedactPatterns]. Sensitive keys also mask whole
+groups and the fields of struct and map values, while sensitive patterns also
+apply to the texts of errors and [fmt.Stringer] values. Packages can register
+their sensitive keys and patterns from their init functions.
+
+Commands must call clippy.AfterCommand after they have run, in order to close
+log files and to stop reacting to signals and serving the log level control.
 
 [lmittmann/tint]: https://github.com/lmittmann/tint
 */
@@ -0,0 +1,133 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"log/slog"
+	"runtime"
+	"slices"
+)
+
+// field is a flattened attribute, with group names prefixed to the key and
+// separated by dots, and the value in textual representation.
+type field struct {
+	Key   string
+	Value string
+}
+
+// flatAttrs implements flattening attributes into fields for handlers that
+// don't support nested groups, such as the journald and syslog handlers. It
+// keeps track of the attributes and groups added to a handler.
+type flatAttrs struct {
+	replaceAttr func([]string, slog.Attr) slog.Attr
+	groups      []string // currently open groups.
+	fields      []field  // fields from attributes added to the handler.
+}
+
+// withAttrs returns a copy with the specified attributes added.
+func (f flatAttrs) withAttrs(attrs []slog.Attr) flatAttrs {
+	fields := slices.Clip(f.fields)
+	for _, attr := range attrs {
+		fields = f.appendAttr(fields, f.groups, attr)
+	}
+	f.fields = fields
+	return f
+}
+
+// withGroup returns a copy with the specified group opened.
+func (f flatAttrs) withGroup(name string) flatAttrs {
+	if name == "" {
+		return f
+	}
+	f.groups = append(slices.Clip(f.groups), name)
+	return f
+}
+
+// flatten returns the fields of the handler followed by the fields of the
+// attributes of the specified record.
+func (f flatAttrs) flatten(r slog.Record) []field {
+	fields := slices.Clone(f.fields)
+	r.Attrs(func(attr slog.Attr) bool {
+		fields = f.appendAttr(fields, f.groups, attr)
+		return true
+	})
+	return fields
+}
+
+// replace the specified built-in attribute, such as the message, returning
+// the replaced attribute.
+func (f flatAttrs) replace(attr slog.Attr) slog.Attr {
+	if f.replaceAttr == nil {
+		return attr
+	}
+	return f.replaceAttr(nil, attr)
+}
+
+// appendAttr appends the field(s) of the specified attribute inside the
+// specified groups.
+func (f flatAttrs) appendAttr(fields []field, groups []string, attr slog.Attr) []field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() == slog.KindGroup {
+		groupAttrs := attr.Value.Group()
+		if len(groupAttrs) == 0 {
+			return fields
+		}
+		if attr.Key != "" {
+			groups = append(slices.Clip(groups), attr.Key)
+		}
+		for _, groupAttr := range groupAttrs {
+			fields = f.appendAttr(fields, groups, groupAttr)
+		}
+		return fields
+	}
+	if f.replaceAttr != nil {
+		attr = f.replaceAttr(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	key := attr.Key
+	for _, group := range slices.Backward(groups) {
+		key = group + "." + key
+	}
+	return append(fields, field{Key: key, Value: attr.Value.String()})
+}
+
+// severity returns the syslog severity for the specified log level.
+func severity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3 // error
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+// source returns the source code location attribute for the specified program
+// counter, after replacement. The value of the returned attribute usually is
+// a *[slog.Source], unless replaced by something else.
+func (f flatAttrs) source(pc uintptr) slog.Attr {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return f.replace(slog.Any(slog.SourceKey, &slog.Source{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}))
+}
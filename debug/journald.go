@@ -0,0 +1,148 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// journalHandler is a [slog.Handler] writing records as datagrams in the
+// native systemd journal protocol, see
+// https://systemd.io/JOURNAL_NATIVE_PROTOCOL/. The attributes of records
+// become journal fields with upper-cased names prefixed with "SLOG_", where
+// groups are joined using underscores.
+type journalHandler struct {
+	conn       net.Conn
+	level      slog.Leveler
+	identifier string
+	addSource  bool
+	flat       flatAttrs
+}
+
+var _ slog.Handler = (*journalHandler)(nil)
+
+// newJournalHandler returns a new journalHandler writing to the specified
+// (datagram) connection, using the specified syslog identifier and handler
+// options.
+func newJournalHandler(conn net.Conn, identifier string, opts *slog.HandlerOptions) *journalHandler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	h := &journalHandler{
+		conn:       conn,
+		level:      opts.Level,
+		identifier: identifier,
+		addSource:  opts.AddSource,
+		flat:       flatAttrs{replaceAttr: opts.ReplaceAttr},
+	}
+	if h.level == nil {
+		h.level = slog.LevelInfo
+	}
+	return h
+}
+
+func (h *journalHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *journalHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	if msg := h.flat.replace(slog.String(slog.MessageKey, r.Message)); !msg.Equal(slog.Attr{}) {
+		appendJournalField(&buf, "MESSAGE", msg.Value.String())
+	}
+	appendJournalField(&buf, "PRIORITY", strconv.Itoa(severity(r.Level)))
+	if h.identifier != "" {
+		appendJournalField(&buf, "SYSLOG_IDENTIFIER", h.identifier)
+	}
+	if h.addSource && r.PC != 0 {
+		switch source := h.flat.source(r.PC); src := source.Value.Any().(type) {
+		case *slog.Source:
+			appendJournalField(&buf, "CODE_FILE", src.File)
+			appendJournalField(&buf, "CODE_LINE", strconv.Itoa(src.Line))
+			appendJournalField(&buf, "CODE_FUNC", src.Function)
+		default:
+			if !source.Equal(slog.Attr{}) {
+				appendJournalField(&buf, "CODE_FILE", source.Value.String())
+			}
+		}
+	}
+	for _, f := range h.flat.flatten(r) {
+		appendJournalField(&buf, journalFieldName(f.Key), f.Value)
+	}
+	_, err := h.conn.Write(buf.Bytes())
+	return err
+}
+
+func (h *journalHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.flat = h.flat.withAttrs(attrs)
+	return &h2
+}
+
+func (h *journalHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.flat = h.flat.withGroup(name)
+	return &h2
+}
+
+// appendJournalField appends the specified field in native journal protocol
+// serialization. Values containing newlines are serialized in binary form,
+// prefixed by their little-endian 64 bit length.
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.ContainsRune(value, '\n') {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldPrefix prefixes the journal field names of attributes, so that
+// attributes cannot clash with the fields set by the journal handler itself,
+// such as MESSAGE and PRIORITY, nor with other well-known journal fields.
+const journalFieldPrefix = "SLOG_"
+
+// journalFieldName returns a valid journal field name for the specified
+// (dotted) attribute key: it starts with [journalFieldPrefix], consists only of
+// upper-case letters, digits, and underscores, and has at most 64 characters.
+func journalFieldName(key string) string {
+	name := []byte(journalFieldPrefix + strings.ToUpper(key))
+	for idx, ch := range name {
+		if (ch < 'A' || ch > 'Z') && (ch < '0' || ch > '9') {
+			name[idx] = '_'
+		}
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return string(name)
+}
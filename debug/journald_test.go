@@ -0,0 +1,162 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// listenUnixgram returns a unix datagram socket stand-in for journald or
+// syslog, closing it when the current test is done.
+func listenUnixgram(name string) (*net.UnixConn, string) {
+	GinkgoHelper()
+	path := filepath.Join(GinkgoT().TempDir(), name)
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	Expect(err).NotTo(HaveOccurred())
+	DeferCleanup(func() { _ = conn.Close() })
+	return conn, path
+}
+
+// receive returns the next datagram received on the specified connection.
+func receive(conn net.PacketConn) string {
+	GinkgoHelper()
+	Expect(conn.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+	buf := make([]byte, 65536)
+	n, _, err := conn.ReadFrom(buf)
+	Expect(err).NotTo(HaveOccurred())
+	return string(buf[:n])
+}
+
+var _ = Describe("journald logging", func() {
+
+	var rootCmd *cobra.Command
+
+	BeforeEach(func() {
+		rootCmd = newLoggingRootCmd(&bytes.Buffer{})
+	})
+
+	It("sanitizes field names", func() {
+		Expect(journalFieldName("foo")).To(Equal("SLOG_FOO"))
+		Expect(journalFieldName("grp.foo-bar")).To(Equal("SLOG_GRP_FOO_BAR"))
+		Expect(journalFieldName("_foo")).To(Equal("SLOG__FOO"))
+		Expect(journalFieldName("42")).To(Equal("SLOG_42"))
+		Expect(journalFieldName("")).To(Equal("SLOG_"))
+		Expect(journalFieldName(string(bytes.Repeat([]byte("a"), 100)))).To(HaveLen(64))
+	})
+
+	It("serializes fields", func() {
+		var buf bytes.Buffer
+		appendJournalField(&buf, "FOO", "bar")
+		Expect(buf.String()).To(Equal("FOO=bar\n"))
+
+		buf.Reset()
+		appendJournalField(&buf, "FOO", "b\nar")
+		Expect(buf.Bytes()).To(Equal(append(
+			binary.LittleEndian.AppendUint64([]byte("FOO\n"), 4), "b\nar\n"...)))
+	})
+
+	It("sends records to journald", func() {
+		journal, path := listenUnixgram("journal.sock")
+		rootCmd.SetArgs([]string{
+			"--" + LogTargetFlagName + "=journald",
+			"--" + LogTargetAddrFlagName + "=unix:" + path,
+			"--" + LogSourceFlagName,
+		})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(clippy.AfterCommand(cmd, nil)).To(Succeed()) }()
+
+		slog.Default().WithGroup("grp").With("answer", 42).Warn("hellorld!\nor not", "foo", "bar")
+		datagram := receive(journal)
+		Expect(datagram).To(HavePrefix("MESSAGE\n"))
+		Expect(datagram).To(ContainSubstring("hellorld!\nor not\n"))
+		Expect(datagram).To(ContainSubstring("PRIORITY=4\n"))
+		Expect(datagram).To(ContainSubstring("SYSLOG_IDENTIFIER=foo\n"))
+		Expect(datagram).To(MatchRegexp(`CODE_FILE=.*/journald_test\.go\n`))
+		Expect(datagram).To(ContainSubstring("CODE_FUNC="))
+		Expect(datagram).To(ContainSubstring("SLOG_GRP_ANSWER=42\nSLOG_GRP_FOO=bar\n"))
+	})
+
+	It("keeps attributes from clashing with the journal's own fields", func() {
+		journal, path := listenUnixgram("journal.sock")
+		rootCmd.SetArgs([]string{
+			"--" + LogTargetFlagName + "=journald",
+			"--" + LogTargetAddrFlagName + "=unix:" + path,
+		})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(clippy.AfterCommand(cmd, nil)).To(Succeed()) }()
+
+		slog.Info("hellorld!", "message", "foo", "priority", 0, "syslog_identifier", "bar")
+		datagram := receive(journal)
+		Expect(datagram).To(HavePrefix("MESSAGE=hellorld!\nPRIORITY=6\nSYSLOG_IDENTIFIER=foo\n"))
+		Expect(datagram).To(ContainSubstring("SLOG_MESSAGE=foo\nSLOG_PRIORITY=0\nSLOG_SYSLOG_IDENTIFIER=bar\n"))
+		Expect(strings.Count(datagram, "MESSAGE=")).To(Equal(2))
+	})
+
+	It("rejects non-unix journald addresses", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{
+			"--" + LogTargetFlagName + "=journald",
+			"--" + LogTargetAddrFlagName + "=localhost:1234",
+		})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).To(MatchError(ContainSubstring("must be a unix socket path")))
+		Expect(clippy.AfterCommand(cmd, err)).To(Succeed())
+	})
+
+	It("reports when it cannot connect to journald", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{
+			"--" + LogTargetFlagName + "=journald",
+			"--" + LogTargetAddrFlagName + "=unix:" + filepath.Join(GinkgoT().TempDir(), "nada.sock"),
+		})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).To(MatchError(ContainSubstring("cannot connect to journald")))
+		Expect(clippy.AfterCommand(cmd, err)).To(Succeed())
+	})
+
+	It("rejects invalid targets and a log file in combination with a target", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{"--" + LogTargetFlagName + "=foo"})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring(`must be "stderr", "syslog", or "journald"`)))
+
+		_, path := listenUnixgram("journal.sock")
+		rootCmd.SetArgs([]string{
+			"--" + LogTargetFlagName + "=journald",
+			"--" + LogTargetAddrFlagName + "=unix:" + path,
+			"--" + LogFileFlagName + "=" + filepath.Join(GinkgoT().TempDir(), "foo.log"),
+		})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).To(MatchError(ContainSubstring("none of the others can be")))
+		Expect(clippy.AfterCommand(cmd, err)).To(Succeed())
+	})
+
+})
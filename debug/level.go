@@ -0,0 +1,75 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// LevelTrace is the log level for tracing, below the debug level.
+const LevelTrace = slog.LevelDebug - 4
+
+// ParseLevel returns the log level for the specified textual representation,
+// which is either one of the (case-insensitive) names "trace", "debug", "info",
+// "warn", or "error", optionally followed by an offset as in "info+2", or an
+// integer.
+func ParseLevel(s string) (slog.Level, error) {
+	s = strings.TrimSpace(s)
+	if level, err := strconv.Atoi(s); err == nil {
+		return slog.Level(level), nil
+	}
+	name, offset, sign := s, "", ""
+	if idx := strings.IndexAny(s, "+-"); idx >= 0 {
+		name, sign, offset = s[:idx], s[idx:idx+1], s[idx+1:]
+	}
+	if strings.EqualFold(name, "trace") {
+		level := LevelTrace
+		if offset != "" {
+			delta, err := strconv.Atoi(offset)
+			if err != nil {
+				return 0, fmt.Errorf("invalid log level %q", s)
+			}
+			if sign == "-" {
+				delta = -delta
+			}
+			level += slog.Level(delta)
+		}
+		return level, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q, must be trace, debug, info, warn, error, or an integer", s)
+	}
+	return level, nil
+}
+
+// levelValue is a flag value accepting log level names and integers.
+type levelValue slog.Level
+
+func (l *levelValue) String() string { return slog.Level(*l).String() }
+
+func (l *levelValue) Set(s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	*l = levelValue(level)
+	return nil
+}
+
+func (l *levelValue) Type() string { return "level" }
@@ -0,0 +1,60 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"log/slog"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("log levels", func() {
+
+	DescribeTable("parsing log levels",
+		func(s string, expected slog.Level) {
+			Expect(ParseLevel(s)).To(Equal(expected))
+		},
+		Entry(nil, "trace", LevelTrace),
+		Entry(nil, "TRACE+1", LevelTrace+1),
+		Entry(nil, "trace-2", LevelTrace-2),
+		Entry(nil, "debug", slog.LevelDebug),
+		Entry(nil, "Info", slog.LevelInfo),
+		Entry(nil, " warn ", slog.LevelWarn),
+		Entry(nil, "ERROR+2", slog.LevelError+2),
+		Entry(nil, "-8", slog.Level(-8)),
+		Entry(nil, "42", slog.Level(42)),
+	)
+
+	DescribeTable("rejecting invalid log levels",
+		func(s string) {
+			Expect(ParseLevel(s)).Error().To(HaveOccurred())
+		},
+		Entry(nil, ""),
+		Entry(nil, "foo"),
+		Entry(nil, "trace+x"),
+		Entry(nil, "info+x"),
+	)
+
+	It("renders a level flag value", func() {
+		l := levelValue(slog.LevelWarn)
+		Expect(l.String()).To(Equal("WARN"))
+		Expect(l.Type()).To(Equal("level"))
+		Expect(l.Set("foo")).NotTo(Succeed())
+		Expect(l.Set("error")).To(Succeed())
+		Expect(slog.Level(l)).To(Equal(slog.LevelError))
+	})
+
+})
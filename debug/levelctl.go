@@ -0,0 +1,140 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy/internal/endpoint"
+)
+
+// LevelControlPath is the HTTP path of the log level control endpoint.
+const LevelControlPath = "/loglevel"
+
+// LevelVar returns the variable log level of the default logger installed for
+// the specified command, or nil if no logger has been installed (yet). Changing
+// the returned LevelVar changes the log level at runtime, but without logging
+// the change; use [ChangeLevel] instead in order to log the change.
+func LevelVar(cmd *cobra.Command) *slog.LevelVar {
+	if ctx := cmd.Context(); ctx != nil {
+		levelVar, _ := ctx.Value(ctxLevelVar).(*slog.LevelVar)
+		return levelVar
+	}
+	return nil
+}
+
+// ChangeLevel changes the log level of the default logger installed for the
+// specified command at runtime, logging the change (if any) regardless of the
+// new log level. ChangeLevel does nothing if no logger has been installed
+// (yet).
+func ChangeLevel(cmd *cobra.Command, level slog.Level) {
+	if levelVar := LevelVar(cmd); levelVar != nil {
+		setLevel(levelVar, level)
+	}
+}
+
+// setLevel changes the specified variable log level, logging the change (if
+// any) regardless of the new log level.
+func setLevel(levelVar *slog.LevelVar, level slog.Level) {
+	old := levelVar.Level()
+	if level == old {
+		return
+	}
+	levelVar.Set(level)
+	// Directly hand the record to the default logger's handler, bypassing
+	// the level check so that the change always gets logged.
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "log level changed", pcs[0])
+	r.AddAttrs(slog.String("from", old.String()), slog.String("to", level.String()))
+	_ = slog.Default().Handler().Handle(context.Background(), r)
+}
+
+// stepLevel changes the specified variable log level by the specified delta,
+// keeping it within the trace and error levels.
+func stepLevel(levelVar *slog.LevelVar, delta slog.Level) {
+	setLevel(levelVar, min(max(levelVar.Level()+delta, LevelTrace), slog.LevelError))
+}
+
+// stepLevelOnSignals makes the variable log level more verbose on receiving
+// SIGUSR1 and less verbose on receiving SIGUSR2. It returns a function to stop
+// reacting to these signals.
+func stepLevelOnSignals(levelVar *slog.LevelVar) (stop func(), err error) {
+	if len(levelStepSignals) == 0 {
+		return nil, errors.New("log level signals not supported on " + runtime.GOOS)
+	}
+	sigs := make(chan os.Signal, 1)
+	for sig := range levelStepSignals {
+		signal.Notify(sigs, sig)
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case sig := <-sigs:
+				stepLevel(levelVar, levelStepSignals[sig])
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+		<-stopped
+	}, nil
+}
+
+// serveLevelControl serves an HTTP endpoint for getting and setting the
+// variable log level. The address is either a host:port TCP address or a unix
+// socket path prefixed by "unix:". It returns a function to stop serving.
+func serveLevelControl(addr string, levelVar *slog.LevelVar) (stop func() error, err error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LevelControlPath, func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			body, err := io.ReadAll(io.LimitReader(req.Body, 64))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level, err := ParseLevel(string(body))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			setLevel(levelVar, level)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = fmt.Fprintln(w, levelVar.Level().String())
+	})
+	return endpoint.Serve(addr, mux)
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("runtime log level control", func() {
+
+	var rootCmd *cobra.Command
+	var output bytes.Buffer
+
+	BeforeEach(func() {
+		output.Reset()
+
+		rootCmd = newLoggingRootCmd(&output)
+	})
+
+	It("exposes the variable log level", func() {
+		Expect(LevelVar(&cobra.Command{})).To(BeNil())
+		Expect(LevelVar(rootCmd)).To(BeNil())
+		Expect(rootCmd.Execute()).To(Succeed())
+		levelVar := LevelVar(rootCmd)
+		Expect(levelVar).NotTo(BeNil())
+		Expect(levelVar.Level()).To(Equal(slog.LevelInfo))
+
+		slog.Debug("*debug*")
+		Expect(output.String()).To(BeEmpty())
+		levelVar.Set(slog.LevelDebug)
+		slog.Debug("*debug*")
+		Expect(output.String()).To(MatchRegexp(`level=DEBUG msg=\*debug\*`))
+	})
+
+	It("changes the log level, logging the change", func() {
+		Expect(func() { ChangeLevel(rootCmd, slog.LevelDebug) }).NotTo(Panic())
+		Expect(rootCmd.Execute()).To(Succeed())
+		ChangeLevel(rootCmd, slog.LevelError)
+		Expect(LevelVar(rootCmd).Level()).To(Equal(slog.LevelError))
+		Expect(output.String()).To(MatchRegexp(`level=INFO msg="log level changed" from=INFO to=ERROR`))
+		output.Reset()
+		ChangeLevel(rootCmd, slog.LevelError)
+		Expect(output.String()).To(BeEmpty())
+	})
+
+	It("steps log levels, staying within bounds and logging changes", func() {
+		levelVar := &slog.LevelVar{}
+		slog.SetDefault(slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: levelVar})))
+		stepLevel(levelVar, +4)
+		Expect(levelVar.Level()).To(Equal(slog.LevelWarn))
+		Expect(output.String()).To(MatchRegexp(`level=INFO msg="log level changed" from=INFO to=WARN`))
+		stepLevel(levelVar, +4)
+		stepLevel(levelVar, +4)
+		Expect(levelVar.Level()).To(Equal(slog.LevelError))
+		output.Reset()
+		stepLevel(levelVar, +4)
+		Expect(output.String()).To(BeEmpty())
+		for range 5 {
+			stepLevel(levelVar, -4)
+		}
+		Expect(levelVar.Level()).To(Equal(LevelTrace))
+	})
+
+	It("serves a log level control endpoint", func() {
+		sockPath := filepath.Join(GinkgoT().TempDir(), "loglevel.sock")
+		rootCmd.SetArgs([]string{"--" + LogControlFlagName, "unix:" + sockPath})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).NotTo(HaveOccurred())
+		levelVar := LevelVar(cmd)
+
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", sockPath)
+				},
+			},
+		}
+		defer client.CloseIdleConnections()
+		request := func(method, body string) (int, string) {
+			GinkgoHelper()
+			req, err := http.NewRequest(method, "http://loglevel"+LevelControlPath, strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := client.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = resp.Body.Close() }()
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			return resp.StatusCode, string(data)
+		}
+
+		status, body := request(http.MethodGet, "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("INFO\n"))
+
+		status, body = request(http.MethodPut, "debug")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("DEBUG\n"))
+		Expect(levelVar.Level()).To(Equal(slog.LevelDebug))
+		Expect(output.String()).To(MatchRegexp(`msg="log level changed" from=INFO to=DEBUG`))
+
+		status, _ = request(http.MethodPost, "foo")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		status, _ = request(http.MethodDelete, "")
+		Expect(status).To(Equal(http.StatusMethodNotAllowed))
+
+		client.CloseIdleConnections()
+		Expect(clippy.AfterCommand(cmd, nil)).To(Succeed())
+		Expect(sockPath).NotTo(BeAnExistingFile())
+	})
+
+	It("reports when it cannot serve the log level control endpoint", func() {
+		rootCmd.SetArgs([]string{"--" + LogControlFlagName, "unix:" + filepath.Join(GinkgoT().TempDir(), "nada", "sock")})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).To(MatchError(ContainSubstring("cannot serve log level control")))
+		Expect(clippy.AfterCommand(cmd, err)).To(Succeed())
+	})
+
+})
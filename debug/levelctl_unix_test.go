@@ -0,0 +1,56 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+//go:build unix
+
+package debug
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("runtime log level control on signals", func() {
+
+	var rootCmd *cobra.Command
+
+	BeforeEach(func() {
+		rootCmd = newLoggingRootCmd(io.Discard)
+	})
+
+	It("steps the log level on signals", func() {
+		rootCmd.SetArgs([]string{"--" + LogLevelSignalsFlagName})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(clippy.AfterCommand(cmd, nil)).To(Succeed()) }()
+		levelVar := LevelVar(cmd)
+
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR1)).To(Succeed())
+		Eventually(levelVar.Level).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).
+			Should(Equal(slog.LevelDebug))
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR2)).To(Succeed())
+		Eventually(levelVar.Level).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).
+			Should(Equal(slog.LevelInfo))
+	})
+
+})
@@ -0,0 +1,252 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// rotatingFile is an [io.WriteCloser] writing to a log file that optionally
+// gets rotated when reaching a maximum size. Rotated log files get the suffixes
+// ".1", ".2", and so on, with ".1" being the most recent one, and optionally
+// compressed using gzip in the background, adding the suffix ".gz".
+type rotatingFile struct {
+	mu          sync.Mutex
+	name        string
+	maxSize     int64 // maximum size in bytes before rotation, or 0 for no rotation.
+	maxBackups  int   // maximum number of rotated files to keep, or 0 for all.
+	compress    bool  // compress rotated files.
+	f           *os.File
+	size        int64
+	compressing sync.WaitGroup // background compression of the most recently rotated file.
+	compressErr error          // error of the last background compression, if any.
+}
+
+var _ io.WriteCloser = (*rotatingFile)(nil)
+
+// openRotatingFile opens the named log file for appending, creating it as well
+// as its parent directories if necessary.
+func openRotatingFile(name string, maxSize int64, maxBackups int, compress bool) (*rotatingFile, error) {
+	r := &rotatingFile{
+		name:       name,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		compress:   compress,
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open the log file for appending. Must be called with the lock held, unless
+// during construction.
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	r.f = f
+	r.size = info.Size()
+	return nil
+}
+
+// Write to the log file, rotating it first if writing p would exceed the
+// maximum file size. If rotation fails, Write still writes to the (reopened)
+// log file, but additionally returns the rotation error.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		rotateErr = r.rotate()
+		if r.f == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+// Reopen the log file, such as after it has been rotated by an external tool
+// like logrotate.
+func (r *rotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return os.ErrClosed
+	}
+	err := r.f.Close()
+	return errors.Join(err, r.open())
+}
+
+// Close the log file, waiting for any background compression to finish.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return errors.Join(err, r.compressed())
+}
+
+// compressed waits for any background compression to finish and returns its
+// error, if any. Must be called with the lock held.
+func (r *rotatingFile) compressed() error {
+	r.compressing.Wait()
+	err := r.compressErr
+	r.compressErr = nil
+	return err
+}
+
+// backupName returns the name of the no'th rotated log file, with or without
+// the compression suffix.
+func (r *rotatingFile) backupName(no int, compressed bool) string {
+	name := r.name + "." + strconv.Itoa(no)
+	if compressed {
+		name += ".gz"
+	}
+	return name
+}
+
+// exists returns true if the named file exists.
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// rotate the log file, shifting the existing rotated files and removing the
+// oldest rotated files in excess of the maximum number of backups. The log file
+// always gets reopened, even if rotation fails, so that logging continues. Must
+// be called with the lock held.
+func (r *rotatingFile) rotate() error {
+	err := errors.Join(r.f.Close(), r.compressed())
+	r.f = nil
+	if shiftErr := r.shift(); shiftErr != nil {
+		return errors.Join(err, shiftErr, r.open())
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	if r.compress {
+		r.compressing.Add(1)
+		go func() {
+			defer r.compressing.Done()
+			r.compressErr = compressFile(r.backupName(1, false), r.backupName(1, true))
+		}()
+	}
+	return err
+}
+
+// shift the existing rotated files as well as the log file itself by one,
+// removing the oldest rotated files in excess of the maximum number of backups.
+func (r *rotatingFile) shift() error {
+	last := 0
+	for exists(r.backupName(last+1, false)) || exists(r.backupName(last+1, true)) {
+		last++
+	}
+	for no := last; no >= 1; no-- {
+		for _, compressed := range []bool{false, true} {
+			name := r.backupName(no, compressed)
+			if !exists(name) {
+				continue
+			}
+			if r.maxBackups > 0 && no >= r.maxBackups {
+				if err := os.Remove(name); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := os.Rename(name, r.backupName(no+1, compressed)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(r.name, r.backupName(1, false))
+}
+
+// compressFile compresses the named source file into the named destination
+// file using gzip, removing the source file afterwards.
+func compressFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err = io.Copy(zw, in); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// reopenOnHangup reopens the specified log file whenever the process receives
+// a SIGHUP, for compatibility with logrotate. It returns a function to stop
+// reopening.
+func reopenOnHangup(r *rotatingFile) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-sigs:
+				_ = r.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+		<-stopped
+	}
+}
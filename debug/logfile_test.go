@@ -0,0 +1,131 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func contents(name string) string {
+	GinkgoHelper()
+	f, err := os.Open(name)
+	Expect(err).NotTo(HaveOccurred())
+	defer func() { _ = f.Close() }()
+	var r io.Reader = f
+	if filepath.Ext(name) == ".gz" {
+		zr, err := gzip.NewReader(f)
+		Expect(err).NotTo(HaveOccurred())
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	Expect(err).NotTo(HaveOccurred())
+	return string(data)
+}
+
+var _ = Describe("log files", func() {
+
+	var name string
+
+	BeforeEach(func() {
+		name = filepath.Join(GinkgoT().TempDir(), "var", "log", "foo.log")
+	})
+
+	It("creates log files and appends to them", func() {
+		r, err := openRotatingFile(name, 0, 0, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Write([]byte("foo\n"))).To(Equal(4))
+		Expect(r.Close()).To(Succeed())
+		Expect(r.Close()).To(Succeed())
+		Expect(r.Write([]byte("foo\n"))).Error().To(MatchError(os.ErrClosed))
+		Expect(r.Reopen()).To(MatchError(os.ErrClosed))
+
+		r, err = openRotatingFile(name, 0, 0, false)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = r.Close() }()
+		Expect(r.Write([]byte("bar\n"))).To(Equal(4))
+		Expect(contents(name)).To(Equal("foo\nbar\n"))
+	})
+
+	It("reports when it cannot create log files", func() {
+		blocker := filepath.Join(GinkgoT().TempDir(), "blocker")
+		Expect(os.WriteFile(blocker, nil, 0o600)).To(Succeed())
+		Expect(openRotatingFile(filepath.Join(blocker, "foo.log"), 0, 0, false)).Error().To(HaveOccurred())
+		Expect(openRotatingFile(GinkgoT().TempDir(), 0, 0, false)).Error().To(HaveOccurred())
+	})
+
+	It("rotates log files, keeping only the maximum number of backups", func() {
+		r, err := openRotatingFile(name, 8, 2, false)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = r.Close() }()
+		for _, line := range []string{"1111\n", "2222\n", "3333\n", "4444\n"} {
+			Expect(r.Write([]byte(line))).To(Equal(5))
+		}
+		Expect(contents(name)).To(Equal("4444\n"))
+		Expect(contents(name + ".1")).To(Equal("3333\n"))
+		Expect(contents(name + ".2")).To(Equal("2222\n"))
+		Expect(name + ".3").NotTo(BeAnExistingFile())
+	})
+
+	It("compresses rotated log files", func() {
+		r, err := openRotatingFile(name, 8, 0, true)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = r.Close() }()
+		for _, line := range []string{"1111\n", "2222\n", "3333\n"} {
+			Expect(r.Write([]byte(line))).To(Equal(5))
+		}
+		Expect(r.Close()).To(Succeed())
+		Expect(contents(name)).To(Equal("3333\n"))
+		Expect(contents(name + ".1.gz")).To(Equal("2222\n"))
+		Expect(contents(name + ".2.gz")).To(Equal("1111\n"))
+		Expect(name + ".1").NotTo(BeAnExistingFile())
+	})
+
+	It("keeps logging when rotation fails", func() {
+		Expect(os.MkdirAll(filepath.Join(name+".1", "blocker"), 0o755)).To(Succeed())
+		r, err := openRotatingFile(name, 8, 1, false)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = r.Close() }()
+		Expect(r.Write([]byte("1111\n"))).To(Equal(5))
+		n, err := r.Write([]byte("2222\n"))
+		Expect(err).To(HaveOccurred())
+		Expect(n).To(Equal(5))
+		Expect(os.RemoveAll(name + ".1")).To(Succeed())
+		Expect(r.Write([]byte("3333\n"))).To(Equal(5))
+		Expect(contents(name)).To(Equal("3333\n"))
+		Expect(contents(name + ".1")).To(Equal("1111\n2222\n"))
+	})
+
+	It("reports failed compression, keeping the uncompressed file", func() {
+		dir := GinkgoT().TempDir()
+		src := filepath.Join(dir, "foo.log.1")
+		Expect(os.WriteFile(src, []byte("foo\n"), 0o600)).To(Succeed())
+		Expect(compressFile(src, dir)).NotTo(Succeed())
+		Expect(compressFile(filepath.Join(dir, "nada"), src+".gz")).NotTo(Succeed())
+		Expect(contents(src)).To(Equal("foo\n"))
+
+		r, err := openRotatingFile(name, 0, 0, true)
+		Expect(err).NotTo(HaveOccurred())
+		r.compressErr = errors.New("foo!")
+		Expect(r.Close()).To(MatchError("foo!"))
+	})
+
+})
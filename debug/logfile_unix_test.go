@@ -0,0 +1,49 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+//go:build unix
+
+package debug
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("log files on SIGHUP", func() {
+
+	It("reopens log files on SIGHUP", func() {
+		name := filepath.Join(GinkgoT().TempDir(), "var", "log", "foo.log")
+		r, err := openRotatingFile(name, 0, 0, false)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = r.Close() }()
+		stop := reopenOnHangup(r)
+		defer stop()
+
+		Expect(r.Write([]byte("foo\n"))).To(Equal(4))
+		Expect(os.Rename(name, name+".old")).To(Succeed())
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGHUP)).To(Succeed())
+		Eventually(func() string { return name }).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).
+			Should(BeAnExistingFile())
+		Expect(r.Write([]byte("bar\n"))).To(Equal(4))
+		Expect(contents(name)).To(Equal("bar\n"))
+		Expect(contents(name + ".old")).To(Equal("foo\n"))
+	})
+
+})
@@ -15,8 +15,13 @@
 package debug
 
 import (
+	"io"
+	"log/slog"
 	"testing"
 
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -25,3 +30,27 @@ func TestDebug(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "clippy/debug package")
 }
+
+// newLoggingRootCmd returns a new root command "foo" that sets up logging to
+// the specified writer before it runs, restoring the default logger when the
+// current test is done.
+func newLoggingRootCmd(w io.Writer) *cobra.Command {
+	GinkgoHelper()
+	oldLogger := slog.Default()
+	DeferCleanup(func() {
+		slog.SetDefault(oldLogger)
+	})
+
+	rootCmd := &cobra.Command{
+		Use: "foo",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return clippy.BeforeCommand(cmd)
+		},
+		RunE:          func(*cobra.Command, []string) error { return nil },
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	SetWriter(rootCmd, w)
+	clippy.AddFlags(rootCmd)
+	return rootCmd
+}
@@ -0,0 +1,297 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces sensitive attribute values and sensitive parts of
+// attribute values in the logging output.
+const Redacted = "[REDACTED]"
+
+// redactions is the registry of sensitive attribute keys and value patterns.
+var redactions = struct {
+	mu       sync.RWMutex
+	keys     map[string]struct{} // lower case keys.
+	patterns []*regexp.Regexp
+}{
+	keys: map[string]struct{}{},
+}
+
+// RedactKeys registers the specified attribute keys as sensitive, so that the
+// values of attributes with these keys get masked in the logging output,
+// including attributes inside groups and whole groups with these keys. The
+// keys also mask the fields of struct and map values logged, where struct
+// fields match either by their name or their JSON name. Keys are matched
+// case-insensitively. RedactKeys can be safely called from init functions.
+func RedactKeys(keys ...string) {
+	redactions.mu.Lock()
+	defer redactions.mu.Unlock()
+	for _, key := range keys {
+		redactions.keys[strings.ToLower(key)] = struct{}{}
+	}
+}
+
+// RedactPatterns registers the specified patterns as sensitive, so that all
+// matches in string attribute values get masked in the logging output,
+// including attributes inside groups, strings inside struct and map values, as
+// well as the texts of errors and [fmt.Stringer] values. RedactPatterns can be
+// safely called from init functions.
+func RedactPatterns(patterns ...*regexp.Regexp) {
+	redactions.mu.Lock()
+	defer redactions.mu.Unlock()
+	redactions.patterns = append(redactions.patterns, patterns...)
+}
+
+// hasRedactions returns true if any sensitive keys or patterns have been
+// registered.
+func hasRedactions() bool {
+	redactions.mu.RLock()
+	defer redactions.mu.RUnlock()
+	return len(redactions.keys) > 0 || len(redactions.patterns) > 0
+}
+
+// redactor is a handler masking sensitive attributes before passing records on
+// to the next handler. In contrast to an attribute replacer, a redactor also
+// sees groups, so it can mask sensitive groups as a whole.
+type redactor struct {
+	next      slog.Handler
+	sensitive bool // true if attributes are now inside a sensitive group.
+}
+
+var _ slog.Handler = (*redactor)(nil)
+
+// newRedactor returns a new redacting handler in front of the specified
+// handler.
+func newRedactor(next slog.Handler) *redactor {
+	return &redactor{next: next}
+}
+
+func (h *redactor) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactor) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	redactions.mu.RLock()
+	r.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redact(attr, h.sensitive))
+		return true
+	})
+	redactions.mu.RUnlock()
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactor) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	redactions.mu.RLock()
+	for _, attr := range attrs {
+		redacted = append(redacted, redact(attr, h.sensitive))
+	}
+	redactions.mu.RUnlock()
+	return &redactor{next: h.next.WithAttrs(redacted), sensitive: h.sensitive}
+}
+
+// WithGroup returns a new handler with the specified group opened. If the
+// group is sensitive, then the new handler masks all attribute values.
+func (h *redactor) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	redactions.mu.RLock()
+	sensitive := h.sensitive || isSensitiveKey(name)
+	redactions.mu.RUnlock()
+	return &redactor{next: h.next.WithGroup(name), sensitive: sensitive}
+}
+
+// maxRedactDepth limits how deep redact descends into nested struct, map,
+// slice, and pointer values, so that cyclic values don't recurse endlessly.
+const maxRedactDepth = 16
+
+// redact masks the value of the specified attribute if its key is sensitive or
+// it is inside a sensitive group, or otherwise masks all sensitive parts of its
+// value. The caller must hold the read lock of the redactions.
+func redact(attr slog.Attr, sensitive bool) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() == slog.KindGroup {
+		attrs := attr.Value.Group()
+		if len(attrs) == 0 {
+			return attr
+		}
+		if attr.Key != "" && (sensitive || isSensitiveKey(attr.Key)) {
+			return slog.String(attr.Key, Redacted)
+		}
+		redacted := make([]slog.Attr, 0, len(attrs))
+		for _, attr := range attrs {
+			redacted = append(redacted, redact(attr, sensitive))
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	}
+	if sensitive || isSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		if value, ok := redactString(attr.Value.String()); ok {
+			return slog.String(attr.Key, value)
+		}
+	case slog.KindAny:
+		if value, ok := redactValue(reflect.ValueOf(attr.Value.Any()), 0); ok {
+			return slog.Any(attr.Key, value)
+		}
+	}
+	return attr
+}
+
+// redactValue returns a copy of the specified value with all sensitive parts
+// masked, and true; or false if there is nothing to mask. Errors and
+// [fmt.Stringer] values get rendered into strings when their texts contain
+// sensitive parts. Structs and maps with string keys get copied into maps
+// with string keys, slices and arrays into slices. The caller must hold the
+// read lock of the redactions.
+func redactValue(v reflect.Value, depth int) (any, bool) {
+	if !v.IsValid() || depth > maxRedactDepth {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+	}
+	if v.CanInterface() {
+		switch value := v.Interface().(type) {
+		case error:
+			return redactString(value.Error())
+		case fmt.Stringer:
+			if text, ok := redactString(value.String()); ok {
+				return text, true
+			}
+		}
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return redactValue(v.Elem(), depth+1)
+	case reflect.String:
+		return redactString(v.String())
+	case reflect.Struct:
+		return redactFields(v, depth)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		redacted := false
+		values := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key := iter.Key().String()
+			values[key], redacted = redactElement(key, iter.Value(), depth, redacted)
+		}
+		if !redacted {
+			return nil, false
+		}
+		return values, true
+	case reflect.Slice, reflect.Array:
+		switch v.Type().Elem().Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.String,
+			reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		default:
+			return nil, false
+		}
+		redacted := false
+		values := make([]any, v.Len())
+		for i := range values {
+			values[i], redacted = redactElement("", v.Index(i), depth, redacted)
+		}
+		if !redacted {
+			return nil, false
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// redactFields returns a map of the exported fields of the specified struct
+// value with all sensitive parts masked, and true; or false if there is
+// nothing to mask. The fields are keyed by their JSON names, if any.
+func redactFields(v reflect.Value, depth int) (any, bool) {
+	redacted := false
+	values := map[string]any{}
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := field.Name
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+		default:
+			key = name
+		}
+		if isSensitiveKey(field.Name) {
+			values[key] = Redacted
+			redacted = true
+			continue
+		}
+		values[key], redacted = redactElement(key, v.Field(i), depth, redacted)
+	}
+	if !redacted {
+		return nil, false
+	}
+	return values, true
+}
+
+// redactElement returns the specified element of a struct, map, or slice
+// value with all sensitive parts masked, as well as whether this or any
+// previous element of the same value got masked.
+func redactElement(key string, v reflect.Value, depth int, redacted bool) (any, bool) {
+	if key != "" && isSensitiveKey(key) {
+		return Redacted, true
+	}
+	if value, ok := redactValue(v, depth+1); ok {
+		return value, true
+	}
+	if !v.CanInterface() {
+		return nil, redacted
+	}
+	return v.Interface(), redacted
+}
+
+// isSensitiveKey returns true if the specified key is sensitive. The caller
+// must hold the read lock of the redactions.
+func isSensitiveKey(key string) bool {
+	_, ok := redactions.keys[strings.ToLower(key)]
+	return ok
+}
+
+// redactString returns the specified string with all sensitive parts masked,
+// and true; or false if there is nothing to mask. The caller must hold the read
+// lock of the redactions.
+func redactString(s string) (string, bool) {
+	redacted := s
+	for _, pattern := range redactions.patterns {
+		redacted = pattern.ReplaceAllLiteralString(redacted, Redacted)
+	}
+	return redacted, redacted != s
+}
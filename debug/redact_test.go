@@ -0,0 +1,145 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("redacting sensitive information", func() {
+
+	var rootCmd *cobra.Command
+	var output bytes.Buffer
+
+	BeforeEach(func() {
+		redactions.mu.Lock()
+		oldKeys := maps.Clone(redactions.keys)
+		oldPatterns := slices.Clone(redactions.patterns)
+		redactions.mu.Unlock()
+		DeferCleanup(func() {
+			redactions.mu.Lock()
+			defer redactions.mu.Unlock()
+			redactions.keys = oldKeys
+			redactions.patterns = oldPatterns
+		})
+
+		output.Reset()
+
+		rootCmd = newLoggingRootCmd(&output)
+	})
+
+	It("doesn't redact without sensitive keys or patterns", func() {
+		Expect(hasRedactions()).To(BeFalse())
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!", "password", "secret")
+		Expect(output.String()).To(ContainSubstring("password=secret"))
+	})
+
+	It("redacts sensitive keys, also inside groups", func() {
+		RedactKeys("Password", "token")
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!", "password", "secret", "user", "foo",
+			slog.Group("auth", "TOKEN", 42))
+		Expect(output.String()).To(ContainSubstring(`password=[REDACTED] user=foo auth.TOKEN=[REDACTED]`))
+	})
+
+	It("redacts sensitive patterns in values", func() {
+		RedactPatterns(regexp.MustCompile(`Bearer \S+`))
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!", "header", "Authorization: Bearer abc123", "answer", 42)
+		Expect(output.String()).To(ContainSubstring(`header="Authorization: [REDACTED]" answer=42`))
+	})
+
+	It("redacts sensitive groups", func() {
+		RedactKeys("auth")
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!", slog.Group("auth", "user", "foo", "token", "secret"), "answer", 42)
+		slog.Default().WithGroup("AUTH").Info("hellorld!", "user", "bar", slog.Group("", "token", "secret"))
+		Expect(output.String()).To(ContainSubstring(`msg=hellorld! auth=[REDACTED] answer=42`))
+		Expect(output.String()).To(ContainSubstring(`msg=hellorld! AUTH.user=[REDACTED] AUTH.token=[REDACTED]`))
+		Expect(output.String()).NotTo(ContainSubstring("secret"))
+	})
+
+	It("redacts sensitive fields of struct and map values", func() {
+		type credentials struct {
+			User   string
+			Secret string `json:"token,omitempty"`
+		}
+		type config struct {
+			Name  string
+			Auth  *credentials
+			Hosts map[string]string
+			port  int
+		}
+		RedactKeys("token", "password")
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!", "config", config{
+			Name:  "foo",
+			Auth:  &credentials{User: "bar", Secret: "secret"},
+			Hosts: map[string]string{"password": "secret"},
+			port:  42,
+		}, "answer", []int{42})
+		Expect(output.String()).To(ContainSubstring(
+			`config="map[Auth:map[User:bar token:[REDACTED]] Hosts:map[password:[REDACTED]] Name:foo]" answer=[42]`))
+		Expect(output.String()).NotTo(ContainSubstring("secret"))
+	})
+
+	It("leaves struct values without sensitive parts alone", func() {
+		type config struct{ Name string }
+		RedactKeys("token")
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!", "config", config{Name: "foo"})
+		Expect(output.String()).To(ContainSubstring(`config={Name:foo}`))
+	})
+
+	It("redacts sensitive patterns in errors and stringers", func() {
+		RedactPatterns(regexp.MustCompile(`token=\w+`))
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!",
+			"error", fmt.Errorf("invalid query %q", "token=abc123"),
+			"url", &url.URL{Scheme: "http", Host: "localhost", RawQuery: "token=xyz"},
+			"other", errors.New("foo!"))
+		Expect(output.String()).To(ContainSubstring(
+			`error="invalid query \"[REDACTED]\"" url=http://localhost?[REDACTED] other=foo!`))
+	})
+
+	It("redacts JSON and tinted output", func() {
+		RedactKeys("password")
+		rootCmd.SetArgs([]string{"--" + LogFormatFlagName + "=" + string(FormatJSON)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!", slog.Group("user", "password", "secret"))
+		Expect(output.String()).To(ContainSubstring(`"user":{"password":"[REDACTED]"}`))
+
+		output.Reset()
+		rootCmd.SetArgs([]string{"--" + LogFormatFlagName + "=" + string(FormatTint)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!", "password", "secret")
+		Expect(output.String()).To(ContainSubstring("[REDACTED]"))
+		Expect(output.String()).NotTo(ContainSubstring("secret"))
+	})
+
+})
@@ -0,0 +1,235 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rate of log records with the same message and level passing per interval.
+type Rate struct {
+	N   int           // maximum number of records per interval; 0 is unlimited.
+	Per time.Duration // interval length.
+}
+
+// String returns the rate in the form "N/s", "N/m", "N/h", or "N/duration",
+// or an empty string if unlimited.
+func (r Rate) String() string {
+	if r.N == 0 {
+		return ""
+	}
+	switch r.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.N)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", r.N)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.N)
+	}
+	return fmt.Sprintf("%d/%s", r.N, r.Per)
+}
+
+// ParseRate returns the rate for the specified textual representation in the
+// form "N/s", "N/m", "N/h", or "N/duration", such as "100/s" or "10/500ms". A
+// rate without interval, such as "100", is per second.
+func ParseRate(s string) (Rate, error) {
+	count, per, _ := strings.Cut(strings.TrimSpace(s), "/")
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q, must be N/s, N/m, N/h, or N/duration", s)
+	}
+	rate := Rate{N: n, Per: time.Second}
+	switch per {
+	case "", "s":
+	case "m":
+		rate.Per = time.Minute
+	case "h":
+		rate.Per = time.Hour
+	default:
+		rate.Per, err = time.ParseDuration(per)
+		if err != nil || rate.Per <= 0 {
+			return Rate{}, fmt.Errorf("invalid rate %q, must be N/s, N/m, N/h, or N/duration", s)
+		}
+	}
+	return rate, nil
+}
+
+// rateValue is a flag value accepting rates.
+type rateValue Rate
+
+func (r *rateValue) String() string { return Rate(*r).String() }
+
+func (r *rateValue) Set(s string) error {
+	rate, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = rateValue(rate)
+	return nil
+}
+
+func (r *rateValue) Type() string { return "rate" }
+
+// samplerKey identifies records to be deduplicated by the sampler.
+type samplerKey struct {
+	level slog.Level
+	msg   string
+}
+
+// samplerCount counts the records with the same samplerKey in the current
+// interval.
+type samplerCount struct {
+	seen       int
+	passed     int
+	suppressed int
+}
+
+// samplerState is the state shared between a sampler and the samplers derived
+// from it using WithAttrs and WithGroup.
+type samplerState struct {
+	out   slog.Handler // for emitting summaries.
+	rate  int          // maximum records per key and interval; 0 is unlimited.
+	after int          // records per key and interval before sampling; 0 disables sampling.
+
+	mu     sync.Mutex
+	counts map[samplerKey]*samplerCount
+}
+
+// sampler is a [slog.Handler] wrapper passing only a sample of records with
+// the same message and level per interval, as well as limiting their rate.
+// Records at error level or above always pass. Summaries of the suppressed
+// records get emitted when an interval ends.
+type sampler struct {
+	next  slog.Handler
+	state *samplerState
+}
+
+var _ slog.Handler = (*sampler)(nil)
+
+// newSampler returns a new sampler wrapping the specified handler, passing at
+// most rate records with the same message and level per interval (unless
+// zero). After the first "after" records per interval (unless zero), it passes
+// only every after-th record.
+func newSampler(next slog.Handler, rate, after int) *sampler {
+	return &sampler{
+		next: next,
+		state: &samplerState{
+			out:    next,
+			rate:   rate,
+			after:  after,
+			counts: map[samplerKey]*samplerCount{},
+		},
+	}
+}
+
+func (s *sampler) Enabled(ctx context.Context, level slog.Level) bool {
+	return s.next.Enabled(ctx, level)
+}
+
+func (s *sampler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError || s.state.allow(samplerKey{level: r.Level, msg: r.Message}) {
+		return s.next.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (s *sampler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampler{next: s.next.WithAttrs(attrs), state: s.state}
+}
+
+func (s *sampler) WithGroup(name string) slog.Handler {
+	return &sampler{next: s.next.WithGroup(name), state: s.state}
+}
+
+// allow returns true if a record with the specified key passes, counting it as
+// either passed or suppressed.
+func (s *samplerState) allow(key samplerKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count, ok := s.counts[key]
+	if !ok {
+		count = &samplerCount{}
+		s.counts[key] = count
+	}
+	count.seen++
+	pass := s.after == 0 || count.seen <= s.after || (count.seen-s.after)%s.after == 0
+	if pass && s.rate > 0 && count.passed >= s.rate {
+		pass = false
+	}
+	if pass {
+		count.passed++
+	} else {
+		count.suppressed++
+	}
+	return pass
+}
+
+// roll ends the current interval, emitting a summary for each message and level
+// with suppressed records.
+func (s *samplerState) roll() {
+	s.mu.Lock()
+	counts := s.counts
+	s.counts = map[samplerKey]*samplerCount{}
+	s.mu.Unlock()
+
+	keys := slices.SortedFunc(func(yield func(samplerKey) bool) {
+		for key, count := range counts {
+			if count.suppressed > 0 && !yield(key) {
+				return
+			}
+		}
+	}, func(a, b samplerKey) int {
+		return cmp.Or(cmp.Compare(a.level, b.level), strings.Compare(a.msg, b.msg))
+	})
+	for _, key := range keys {
+		suppressed := counts[key].suppressed
+		r := slog.NewRecord(time.Now(), key.level, fmt.Sprintf("%d records suppressed", suppressed), 0)
+		r.AddAttrs(slog.String("suppressed_msg", key.msg), slog.Int("suppressed", suppressed))
+		_ = s.out.Handle(context.Background(), r)
+	}
+}
+
+// rollEvery ends the sampler's interval periodically, returning a function to
+// stop, which also emits the summaries of the final interval.
+func (s *sampler) rollEvery(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.state.roll()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		s.state.roll()
+	}
+}
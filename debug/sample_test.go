@@ -0,0 +1,142 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sampling and rate limiting", func() {
+
+	DescribeTable("parsing rates",
+		func(s string, expected Rate) {
+			Expect(ParseRate(s)).To(Equal(expected))
+		},
+		Entry(nil, "100", Rate{N: 100, Per: time.Second}),
+		Entry(nil, "100/s", Rate{N: 100, Per: time.Second}),
+		Entry(nil, "10/m", Rate{N: 10, Per: time.Minute}),
+		Entry(nil, "1/h", Rate{N: 1, Per: time.Hour}),
+		Entry(nil, "5/250ms", Rate{N: 5, Per: 250 * time.Millisecond}),
+	)
+
+	DescribeTable("rejecting invalid rates",
+		func(s string) {
+			Expect(ParseRate(s)).Error().To(MatchError(ContainSubstring("invalid rate")))
+		},
+		Entry(nil, ""),
+		Entry(nil, "-1/s"),
+		Entry(nil, "1/foo"),
+		Entry(nil, "1/-1s"),
+	)
+
+	It("renders rates", func() {
+		Expect(Rate{}.String()).To(BeEmpty())
+		Expect(Rate{N: 1, Per: time.Second}.String()).To(Equal("1/s"))
+		Expect(Rate{N: 2, Per: time.Minute}.String()).To(Equal("2/m"))
+		Expect(Rate{N: 3, Per: time.Hour}.String()).To(Equal("3/h"))
+		Expect(Rate{N: 4, Per: 250 * time.Millisecond}.String()).To(Equal("4/250ms"))
+	})
+
+	It("samples and rate limits, but never drops errors", func() {
+		var output bytes.Buffer
+		s := newSampler(slog.NewTextHandler(&output, nil), 3, 2)
+		logger := slog.New(s).With("foo", "bar")
+		for range 10 {
+			logger.Info("*info*")
+			logger.Error("*error*")
+		}
+		logger.Warn("*warn*")
+		// seen 1 and 2 pass, then every 2nd: 4 (and 6, 8, 10 are above the
+		// rate limit).
+		Expect(strings.Count(output.String(), "*info*")).To(Equal(3))
+		Expect(strings.Count(output.String(), "*error*")).To(Equal(10))
+		Expect(output.String()).To(ContainSubstring(`msg=*warn* foo=bar`))
+
+		output.Reset()
+		s.state.roll()
+		Expect(output.String()).To(MatchRegexp(
+			`^time=\S+ level=INFO msg="7 records suppressed" suppressed_msg=\*info\* suppressed=7\n$`))
+
+		output.Reset()
+		s.state.roll()
+		Expect(output.String()).To(BeEmpty())
+		logger.Info("*info*")
+		Expect(output.String()).To(ContainSubstring("*info*"))
+	})
+
+	It("passes on enabled checks", func() {
+		s := newSampler(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn}), 1, 0)
+		Expect(s.Enabled(context.Background(), slog.LevelInfo)).To(BeFalse())
+		Expect(s.Enabled(context.Background(), slog.LevelWarn)).To(BeTrue())
+		Expect(s.WithGroup("grp").(*sampler).state).To(BeIdenticalTo(s.state))
+	})
+
+	When("configured using flags", func() {
+
+		var rootCmd *cobra.Command
+		var output bytes.Buffer
+
+		BeforeEach(func() {
+			output.Reset()
+
+			rootCmd = newLoggingRootCmd(&output)
+		})
+
+		It("limits the rate and emits the final summary after the command", func() {
+			rootCmd.SetArgs([]string{"--" + LogRateFlagName + "=2/h"})
+			cmd, err := rootCmd.ExecuteC()
+			Expect(err).NotTo(HaveOccurred())
+			for range 5 {
+				slog.Info("*info*")
+			}
+			Expect(strings.Count(output.String(), "*info*")).To(Equal(2))
+			Expect(clippy.AfterCommand(cmd, nil)).To(Succeed())
+			Expect(output.String()).To(ContainSubstring(`msg="3 records suppressed"`))
+		})
+
+		It("samples", func() {
+			rootCmd.SetArgs([]string{"--" + LogSampleAfterFlagName + "=1"})
+			cmd, err := rootCmd.ExecuteC()
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { Expect(clippy.AfterCommand(cmd, nil)).To(Succeed()) }()
+			Expect(rootCmd.Flags().Lookup(LogRateFlagName).Value.String()).To(BeEmpty())
+			Expect(rootCmd.Flags().Lookup(LogRateFlagName).Value.Type()).To(Equal("rate"))
+		})
+
+		It("rejects invalid flag values", func() {
+			rootCmd.SilenceErrors = true
+			rootCmd.SilenceUsage = true
+			rootCmd.SetArgs([]string{"--" + LogRateFlagName + "=foo"})
+			Expect(rootCmd.Execute()).To(MatchError(ContainSubstring("invalid rate")))
+
+			rootCmd.SetArgs([]string{"--" + LogRateFlagName + "=1/s", "--" + LogSampleAfterFlagName + "=-1"})
+			cmd, err := rootCmd.ExecuteC()
+			Expect(err).To(MatchError(ContainSubstring("must not be negative")))
+			Expect(clippy.AfterCommand(cmd, err)).To(Succeed())
+		})
+
+	})
+
+})
@@ -0,0 +1,29 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+//go:build unix
+
+package debug
+
+import (
+	"log/slog"
+	"os"
+	"syscall"
+)
+
+// levelStepSignals maps the signals for stepping the log level to the steps.
+var levelStepSignals = map[os.Signal]slog.Level{
+	syscall.SIGUSR1: -4, // more verbose
+	syscall.SIGUSR2: +4, // less verbose
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	rtdebug "runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// SourceMode controls whether log records include their source code locations,
+// and how these source locations are rendered.
+type SourceMode string
+
+// The supported source location modes.
+const (
+	SourceNone     SourceMode = "none"     // no source locations.
+	SourceFull     SourceMode = "full"     // source locations with full file paths.
+	SourceRelative SourceMode = "relative" // file paths relative to their module roots.
+)
+
+// sourceValue is a flag value only accepting the supported source location
+// modes.
+type sourceValue SourceMode
+
+func (s *sourceValue) String() string { return string(*s) }
+
+func (s *sourceValue) Set(v string) error {
+	switch mode := SourceMode(v); mode {
+	case SourceNone, SourceFull, SourceRelative:
+		*s = sourceValue(mode)
+		return nil
+	}
+	return fmt.Errorf("must be %q, %q, or %q", SourceNone, SourceFull, SourceRelative)
+}
+
+func (s *sourceValue) Type() string { return "mode" }
+
+// SetAddSource allows API users to enable source code locations in log records
+// by default; the "--log-source" flag takes precedence.
+func SetAddSource(cmd *cobra.Command, mode SourceMode) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.SetContext(context.WithValue(ctx, ctxSourceMode, mode))
+}
+
+// relativeSource replaces the file path of source locations with the file
+// path relative to the root of the module the file belongs to.
+func relativeSource(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key != slog.SourceKey || len(groups) != 0 {
+		return attr
+	}
+	src, ok := attr.Value.Any().(*slog.Source)
+	if !ok {
+		return attr
+	}
+	relsrc := *src
+	relsrc.File = moduleRelative(src.Function, src.File)
+	return slog.Any(attr.Key, &relsrc)
+}
+
+// buildModules returns the path of the main package as well as the paths of
+// the modules the binary was built from, ordered from longest to shortest path.
+var buildModules = sync.OnceValues(func() (string, []string) {
+	info, ok := rtdebug.ReadBuildInfo()
+	if !ok {
+		return "", nil
+	}
+	modules := []string{info.Main.Path}
+	for _, dep := range info.Deps {
+		modules = append(modules, dep.Path)
+	}
+	slices.SortFunc(modules, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	return info.Path, modules
+})
+
+// moduleRelative returns the file path of the specified function's source
+// file relative to the root directory of the module the function belongs to.
+// As the build machine's source tree usually isn't available at run time, the
+// relative path is derived from the function's package path and the module
+// paths recorded in the binary's build information. If the module cannot be
+// determined, moduleRelative returns the file path unchanged.
+func moduleRelative(function, file string) string {
+	pkgPath := packagePath(function)
+	if pkgPath == "" {
+		return file
+	}
+	mainPath, modules := buildModules()
+	if pkgPath == "main" {
+		if mainPath == "" {
+			return file
+		}
+		pkgPath = mainPath
+	}
+	base := filepath.Base(file)
+	for _, module := range modules {
+		if module == "" {
+			continue
+		}
+		if pkgPath == module {
+			return base
+		}
+		if dir, ok := strings.CutPrefix(pkgPath, module+"/"); ok {
+			return path.Join(dir, base)
+		}
+	}
+	if first, _, _ := strings.Cut(pkgPath, "/"); !strings.Contains(first, ".") {
+		return path.Join(pkgPath, base) // standard library package
+	}
+	return file
+}
+
+// packagePath returns the path of the package the specified fully qualified
+// function name belongs to, or "" if the function name isn't qualified.
+func packagePath(function string) string {
+	lastSlash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[lastSlash+1:], '.')
+	if dot < 0 {
+		return ""
+	}
+	// dots in the last path element are escaped in function names.
+	return function[:lastSlash+1] + strings.ReplaceAll(function[lastSlash+1:lastSlash+1+dot], "%2e", ".")
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("source code locations", func() {
+
+	var rootCmd *cobra.Command
+	var output bytes.Buffer
+
+	BeforeEach(func() {
+		output.Reset()
+
+		rootCmd = newLoggingRootCmd(&output)
+	})
+
+	It("doesn't add source code locations by default", func() {
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).NotTo(ContainSubstring("source="))
+	})
+
+	It("adds full source code locations", func() {
+		wd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		rootCmd.SetArgs([]string{"--" + LogSourceFlagName})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(ContainSubstring("source=" + filepath.Join(wd, "source_test.go") + ":"))
+	})
+
+	It("adds relative source code locations", func() {
+		rootCmd.SetArgs([]string{"--" + LogSourceFlagName + "=" + string(SourceRelative)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(MatchRegexp(`source=debug/source_test.go:\d+ msg=hellorld!`))
+	})
+
+	It("adds relative source code locations to JSON and tinted output", func() {
+		rootCmd.SetArgs([]string{"--" + LogSourceFlagName + "=" + string(SourceRelative),
+			"--" + LogFormatFlagName + "=" + string(FormatJSON)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(MatchRegexp(`"source":{"function":".*","file":"debug/source_test.go","line":\d+}`))
+
+		output.Reset()
+		rootCmd.SetArgs([]string{"--" + LogSourceFlagName + "=" + string(SourceRelative),
+			"--" + LogFormatFlagName + "=" + string(FormatTint)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(MatchRegexp(`debug/source_test.go:\d+`))
+	})
+
+	It("changes the default and lets the flag take precedence", func() {
+		SetAddSource(rootCmd, SourceRelative)
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).To(ContainSubstring("source=debug/source_test.go:"))
+
+		output.Reset()
+		rootCmd.SetArgs([]string{"--" + LogSourceFlagName + "=" + string(SourceNone)})
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("hellorld!")
+		Expect(output.String()).NotTo(ContainSubstring("source="))
+	})
+
+	It("rejects invalid modes", func() {
+		rootCmd.SetArgs([]string{"--" + LogSourceFlagName + "=foo"})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring(`must be "none", "full", or "relative"`)))
+	})
+
+	It("derives relative paths from package and module paths", func() {
+		Expect(moduleRelative("github.com/thediveo/clippy/debug.(*foo).Bar", "/nowhere/debug/foo.go")).
+			To(Equal("debug/foo.go"))
+		Expect(moduleRelative("github.com/thediveo/clippy.AddFlags.func1", "/nowhere/clippy.go")).
+			To(Equal("clippy.go"))
+		Expect(moduleRelative("github.com/spf13/cobra.(*Command).Execute", "/go/pkg/mod/github.com/spf13/cobra@v1.0.0/command.go")).
+			To(Equal("command.go"))
+		Expect(moduleRelative("go.yaml.in/yaml/v3.Unmarshal", "/go/pkg/mod/go.yaml.in/yaml/v3@v3.0.4/yaml.go")).
+			To(Equal("yaml.go"))
+		Expect(moduleRelative("log/slog.(*Logger).Info", "/usr/local/go/src/log/slog/logger.go")).
+			To(Equal("log/slog/logger.go"))
+		Expect(packagePath("example.org/foo/bar%2ev2.Baz")).To(Equal("example.org/foo/bar.v2"))
+	})
+
+	It("leaves paths alone that cannot be made relative", func() {
+		Expect(moduleRelative("", "foo/bar.go")).To(Equal("foo/bar.go"))
+		Expect(moduleRelative("example.org/foo.Bar", "/nowhere/foo.go")).To(Equal("/nowhere/foo.go"))
+
+		attr := slog.String(slog.SourceKey, "foo")
+		Expect(relativeSource(nil, attr)).To(Equal(attr))
+		attr = slog.Any(slog.SourceKey, &slog.Source{File: "foo.go"})
+		Expect(relativeSource([]string{"group"}, attr)).To(Equal(attr))
+	})
+
+})
@@ -0,0 +1,175 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+)
+
+// SyslogSDID is the structured data ID under which syslogHandler emits the
+// attributes of records as structured data parameters. It uses the
+// enterprise number 32473 reserved for documentation purposes, see RFC 5612.
+const SyslogSDID = "slog@32473"
+
+// syslogFacilityUser is the syslog facility "user-level messages".
+const syslogFacilityUser = 1
+
+// syslogHandler is a [slog.Handler] writing records as RFC 5424 syslog
+// messages, with the attributes of records as structured data parameters.
+// Groups are joined using dots.
+type syslogHandler struct {
+	conn      net.Conn
+	level     slog.Leveler
+	hostname  string
+	appName   string
+	procID    string
+	addSource bool
+	flat      flatAttrs
+}
+
+var _ slog.Handler = (*syslogHandler)(nil)
+
+// newSyslogHandler returns a new syslogHandler writing to the specified
+// (datagram) connection, using the specified app name and handler options.
+func newSyslogHandler(conn net.Conn, appName string, opts *slog.HandlerOptions) *syslogHandler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	hostname, _ := os.Hostname()
+	h := &syslogHandler{
+		conn:      conn,
+		level:     opts.Level,
+		hostname:  syslogHeaderField(hostname, 255),
+		appName:   syslogHeaderField(appName, 48),
+		procID:    fmt.Sprint(os.Getpid()),
+		addSource: opts.AddSource,
+		flat:      flatAttrs{replaceAttr: opts.ReplaceAttr},
+	}
+	if h.level == nil {
+		h.level = slog.LevelInfo
+	}
+	return h
+}
+
+func (h *syslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *syslogHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	timestamp := "-"
+	if !r.Time.IsZero() {
+		timestamp = r.Time.Format("2006-01-02T15:04:05.000000Z07:00")
+	}
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s - ",
+		syslogFacilityUser*8+severity(r.Level), timestamp, h.hostname, h.appName, h.procID)
+
+	fields := h.flat.flatten(r)
+	if h.addSource && r.PC != 0 {
+		switch source := h.flat.source(r.PC); src := source.Value.Any().(type) {
+		case *slog.Source:
+			fields = append(fields, field{Key: slog.SourceKey, Value: fmt.Sprintf("%s:%d", src.File, src.Line)})
+		default:
+			if !source.Equal(slog.Attr{}) {
+				fields = append(fields, field{Key: source.Key, Value: source.Value.String()})
+			}
+		}
+	}
+	if len(fields) == 0 {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteString("[" + SyslogSDID)
+		for _, f := range fields {
+			buf.WriteByte(' ')
+			buf.WriteString(syslogParamName(f.Key))
+			buf.WriteString(`="`)
+			buf.WriteString(syslogParamValueEscaper.Replace(f.Value))
+			buf.WriteByte('"')
+		}
+		buf.WriteByte(']')
+	}
+	if msg := h.flat.replace(slog.String(slog.MessageKey, r.Message)); !msg.Equal(slog.Attr{}) {
+		buf.WriteByte(' ')
+		buf.WriteString(msg.Value.String())
+	}
+	_, err := h.conn.Write(buf.Bytes())
+	return err
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.flat = h.flat.withAttrs(attrs)
+	return &h2
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.flat = h.flat.withGroup(name)
+	return &h2
+}
+
+// syslogParamValueEscaper escapes the characters that must be escaped inside
+// structured data parameter values.
+var syslogParamValueEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// syslogHeaderField returns the specified header field value consisting only
+// of printable US-ASCII characters and limited to the specified maximum
+// length, or the nil value "-" if empty.
+func syslogHeaderField(s string, maxlen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	if len(s) > maxlen {
+		s = s[:maxlen]
+	}
+	return s
+}
+
+// syslogParamName returns a valid structured data parameter name for the
+// specified attribute key, replacing invalid characters with underscores and
+// limiting the name to 32 characters.
+func syslogParamName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+	if name == "" {
+		return "_"
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
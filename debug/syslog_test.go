@@ -0,0 +1,111 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("syslog logging", func() {
+
+	var rootCmd *cobra.Command
+
+	BeforeEach(func() {
+		rootCmd = newLoggingRootCmd(&bytes.Buffer{})
+	})
+
+	It("sanitizes header fields and parameter names", func() {
+		Expect(syslogHeaderField("", 10)).To(Equal("-"))
+		Expect(syslogHeaderField("foo bär", 10)).To(Equal("foobr"))
+		Expect(syslogHeaderField("foobar", 3)).To(Equal("foo"))
+
+		Expect(syslogParamName("")).To(Equal("_"))
+		Expect(syslogParamName(`a b=c]d"e`)).To(Equal("a_b_c_d_e"))
+		Expect(syslogParamName(string(bytes.Repeat([]byte("a"), 40)))).To(HaveLen(32))
+	})
+
+	It("sends records to a unix datagram socket", func() {
+		syslog, path := listenUnixgram("log.sock")
+		RedactKeys("password")
+		DeferCleanup(func() {
+			redactions.mu.Lock()
+			defer redactions.mu.Unlock()
+			delete(redactions.keys, "password")
+		})
+		rootCmd.SetArgs([]string{
+			"--" + LogTargetFlagName + "=syslog",
+			"--" + LogTargetAddrFlagName + "=unix:" + path,
+		})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(clippy.AfterCommand(cmd, nil)).To(Succeed()) }()
+
+		slog.Default().WithGroup("grp").With("answer", 42).Error("hellorld!",
+			"foo", `"b]a\r"`, "password", "secret")
+		Expect(receive(syslog)).To(MatchRegexp(
+			`^<11>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S+ \S+ foo %d - `+
+				regexp.QuoteMeta(`[`+SyslogSDID+` grp.answer="42" grp.foo="\"b\]a\\r\"" grp.password="`+syslogParamValueEscaper.Replace(Redacted)+`"] hellorld!`)+`$`,
+			os.Getpid()))
+
+		slog.Debug("*debug*")
+		slog.Info("*info*")
+		Expect(receive(syslog)).To(MatchRegexp(`^<14>1 .* - - \*info\*$`))
+	})
+
+	It("sends records via UDP", func() {
+		syslog, err := net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer syslog.Close()
+		rootCmd.SetArgs([]string{
+			"--" + LogTargetFlagName + "=syslog",
+			"--" + LogTargetAddrFlagName + "=" + syslog.LocalAddr().String(),
+			"--" + LogLevelFlagName + "=debug",
+			"--" + LogSourceFlagName,
+		})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(clippy.AfterCommand(cmd, nil)).To(Succeed()) }()
+
+		Expect(receive(syslog)).To(MatchRegexp(
+			fmt.Sprintf(`^<15>1 .* \[%s source=".*/debug\.go:\d+"\] debug logging enabled$`,
+				regexp.QuoteMeta(SyslogSDID))))
+		slog.Warn("hellorld!")
+		Expect(receive(syslog)).To(MatchRegexp(`^<12>1 .* hellorld!$`))
+	})
+
+	It("reports when it cannot connect to syslog", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{
+			"--" + LogTargetFlagName + "=syslog",
+			"--" + LogTargetAddrFlagName + "=unix:/nada/log.sock",
+		})
+		cmd, err := rootCmd.ExecuteC()
+		Expect(err).To(MatchError(ContainSubstring("cannot connect to syslog")))
+		Expect(clippy.AfterCommand(cmd, err)).To(Succeed())
+	})
+
+})
@@ -0,0 +1,99 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package debug
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Target of the structured logging output.
+type Target string
+
+// The supported structured logging output targets.
+const (
+	TargetStderr   Target = "stderr"   // stderr, the writer set by [SetWriter], or the log file.
+	TargetSyslog   Target = "syslog"   // RFC 5424 syslog messages via a unix datagram or UDP socket.
+	TargetJournald Target = "journald" // native systemd journal protocol.
+)
+
+// Default addresses of the syslog and journald targets.
+const (
+	DefaultSyslogAddr   = "unix:/dev/log"
+	DefaultJournaldAddr = "unix:/run/systemd/journal/socket"
+)
+
+// targetValue is a flag value only accepting the supported structured logging
+// output targets.
+type targetValue Target
+
+func (t *targetValue) String() string { return string(*t) }
+
+func (t *targetValue) Set(s string) error {
+	switch target := Target(s); target {
+	case TargetStderr, TargetSyslog, TargetJournald:
+		*t = targetValue(target)
+		return nil
+	}
+	return fmt.Errorf("must be %q, %q, or %q", TargetStderr, TargetSyslog, TargetJournald)
+}
+
+func (t *targetValue) Type() string { return "target" }
+
+// newTargetHandler returns a new output handler for the syslog or journald
+// target, as specified by the flags of the specified command, together with
+// the connection to close after the command has run. For the stderr target, it
+// returns a nil handler.
+func newTargetHandler(cmd *cobra.Command, opts *slog.HandlerOptions) (slog.Handler, net.Conn, error) {
+	flag := cmd.Flags().Lookup(LogTargetFlagName)
+	if flag == nil {
+		return nil, nil, nil
+	}
+	addr, _ := cmd.Flags().GetString(LogTargetAddrFlagName)
+	identifier := cmd.Root().Name()
+	switch target := Target(flag.Value.String()); target {
+	case TargetSyslog:
+		conn, err := dialTarget(cmp.Or(addr, DefaultSyslogAddr))
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot connect to syslog, %w", err)
+		}
+		return newSyslogHandler(conn, identifier, opts), conn, nil
+	case TargetJournald:
+		addr = cmp.Or(addr, DefaultJournaldAddr)
+		if !strings.HasPrefix(addr, "unix:") {
+			return nil, nil, fmt.Errorf("journald address must be a unix socket path, got %q", addr)
+		}
+		conn, err := dialTarget(addr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot connect to journald, %w", err)
+		}
+		return newJournalHandler(conn, identifier, opts), conn, nil
+	}
+	return nil, nil, nil
+}
+
+// dialTarget connects to the specified address, which is either a unix
+// datagram socket path prefixed by "unix:", or a host:port UDP address.
+func dialTarget(addr string) (net.Conn, error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return net.Dial("unixgram", path)
+	}
+	return net.Dial("udp", addr)
+}
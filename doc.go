@@ -10,7 +10,9 @@ fashion just by specifying individual plug-in functions, for instance, per each
 individual flag.
 
 Registering [cliplugin.BeforeCommand] functions allows for modular processing of
-flags before the (root) command is run.
+flags before the (root) command is run. Symmetrically, registering
+[cliplugin.AfterCommand] functions allows for modular clean up after the command
+has run, such as closing log files opened in a BeforeCommand function.
 
 Registration of the exported functions should be done in init functions. For
 better modularity, multiple registration-related init functions can perfectly
@@ -19,12 +21,31 @@ names” upon function registration.
 
 It already sufficies when a cmd package references a CLI-related package to pull
 in its CLI flag registrations. A cmd package then should make sure to call
-[AddFlags] and [BeforeCommand] respectively.
+[AddFlags], [BeforeCommand], and [AfterCommand] respectively.
 
 AddFlags should be called after your cmd package has created the root command
 object and is ready for registering flags.
 
+Instead of AddFlags, [AddFlagsTree] can be called after the complete command
+tree has been built. Plugins then can additionally expose a
+[cliplugin.CommandPaths] function in order to register their CLI flags only with
+specific sub commands, such as "serve", instead of the root command.
+
 BeforeCommand should be called from the PersistentPreRunE hook function of your
-cmd package's root command object.
+cmd package's root command object. Alternatively, call [BeforeCommandAll] in
+order to report the errors of all BeforeCommand functions at once, instead of
+only the first error.
+
+Plugins setting flag values from sources other than the command line, such as
+environment variables or configuration files, should record the source of the
+flag values using [SetSource]. [Provenance] then returns the effective values
+of all flags of a command together with their sources.
+
+AfterCommand should be called after the selected command has run, passing it
+the error returned by the command. As cobra skips the PersistentPostRunE hook
+when a command fails, it is best called after executing the root command:
+
+	cmd, err := rootCmd.ExecuteC()
+	err = errors.Join(err, clippy.AfterCommand(cmd, err))
 */
 package clippy
@@ -0,0 +1,21 @@
+/*
+Package env binds all CLI flags to environment variables, so that flags can also
+be set from the environment, such as in containers. Flags explicitly specified
+on the command line take precedence over environment variables. This includes
+flags mutually exclusive with flags specified on the command line: their
+environment variables are then ignored.
+
+The environment variable names are derived from the flag names by converting
+them to upper case, replacing dashes “-” with underscores “_”, and finally
+prefixing them. For instance, the “--debug” flag of an application “myapp” by
+default binds to the environment variable “MYAPP_DEBUG”. Use [SetPrefix] to
+override the default prefix derived from the root command name.
+
+Slice flags take comma-separated lists of values, as on the command line.
+
+The environment variable names are also shown in the help for those flags that
+were registered before or by the time the [cliplugin.SetupCLI] plugin function
+of this package runs. This package places its plugin functions to run last when
+setting up the CLI, and first before the selected command runs.
+*/
+package env
@@ -0,0 +1,133 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/thediveo/clippy"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/go-plugger/v3"
+)
+
+// Register our plugin functions: the setupCLI plugin runs last in order to see
+// as many flags as possible, whereas the beforeCommand plugin runs first so that
+// all other plugins see the flag values taken from the environment.
+func init() {
+	plugger.Group[cliplugin.SetupCLI]().Register(
+		setupCLI, plugger.WithPlugin("clippy/env"), plugger.WithPlacement(">"))
+	plugger.Group[cliplugin.BeforeCommand]().Register(
+		beforeCommand, plugger.WithPlugin("clippy/env"), plugger.WithPlacement("<"))
+}
+
+// ctxKey "namespaces" the context keys this package uses internally for passing
+// API user configuration(s) via contexts attached to cobra commands.
+type ctxKey int
+
+const (
+	ctxPrefix ctxKey = iota
+)
+
+// SetPrefix allows API users to override the default environment variable
+// prefix, which is otherwise derived from the name of the root command. The
+// prefix is used as is, so it usually should end in an underscore “_”, such as
+// in “MYAPP_”.
+//
+// SetPrefix must be called on the root command before clippy.AddFlags.
+func SetPrefix(cmd *cobra.Command, prefix string) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.SetContext(context.WithValue(ctx, ctxPrefix, prefix))
+}
+
+// Name returns the name of the environment variable bound to the flag with the
+// specified name.
+func Name(cmd *cobra.Command, flagName string) string {
+	var prefix string
+	var ok bool
+	if ctx := cmd.Context(); ctx != nil {
+		prefix, ok = ctx.Value(ctxPrefix).(string)
+	}
+	if !ok {
+		prefix = envify(cmd.Root().Name()) + "_"
+	}
+	return prefix + envify(flagName)
+}
+
+// envify converts the specified name into upper case and replaces dashes with
+// underscores.
+func envify(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// setupCLI adds the names of the bound environment variables to the usage of
+// the flags of the specified command and all its sub commands.
+func setupCLI(cmd *cobra.Command) {
+	annotate := func(flag *pflag.Flag) {
+		if flag.Name == "help" {
+			return
+		}
+		envName := "[$" + Name(cmd, flag.Name) + "]"
+		if strings.HasSuffix(flag.Usage, envName) {
+			return
+		}
+		flag.Usage = strings.TrimSpace(flag.Usage + " " + envName)
+	}
+	var walk func(*cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		cmd.PersistentFlags().VisitAll(annotate)
+		cmd.LocalNonPersistentFlags().VisitAll(annotate)
+		for _, subCmd := range cmd.Commands() {
+			walk(subCmd)
+		}
+	}
+	walk(cmd)
+}
+
+// beforeCommand sets all flags of the command about to run that haven't been
+// explicitly specified on the command line to the values of their bound
+// environment variables, if set and not empty. Flags mutually exclusive with
+// flags specified on the command line are left alone, so that the command line
+// takes precedence.
+func beforeCommand(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" {
+			return
+		}
+		if clippy.MutuallyExcluded(cmd, flag.Name, clippy.SourceEnv) {
+			return
+		}
+		envName := Name(cmd, flag.Name)
+		value := os.Getenv(envName)
+		if value == "" {
+			return
+		}
+		if seterr := cmd.Flags().Set(flag.Name, value); seterr != nil {
+			err = fmt.Errorf("invalid value %q for environment variable %s: %w",
+				value, envName, seterr)
+			return
+		}
+		clippy.SetSource(cmd, flag.Name, clippy.SourceEnv, envName)
+	})
+	return err
+}
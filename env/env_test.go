@@ -0,0 +1,149 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package env
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func setenv(name, value string) {
+	GinkgoHelper()
+	Expect(os.Setenv(name, value)).To(Succeed())
+	DeferCleanup(func() { _ = os.Unsetenv(name) })
+}
+
+var _ = Describe("environment variable binding", func() {
+
+	var rootCmd, subCmd *cobra.Command
+
+	var debug bool
+	var tags []string
+	var timeout time.Duration
+	var port int
+
+	BeforeEach(func() {
+		rootCmd = &cobra.Command{
+			Use: "my-app",
+			PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+				return clippy.BeforeCommand(cmd)
+			},
+			RunE: func(*cobra.Command, []string) error { return nil },
+		}
+		rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enables debugging")
+		rootCmd.PersistentFlags().StringSliceVar(&tags, "tags", []string{"foo"}, "tags")
+		rootCmd.Flags().DurationVar(&timeout, "wait-timeout", time.Second, "")
+		subCmd = &cobra.Command{
+			Use:  "serve",
+			RunE: func(*cobra.Command, []string) error { return nil },
+		}
+		subCmd.Flags().IntVar(&port, "port", 42, "port to serve on")
+		rootCmd.AddCommand(subCmd)
+		clippy.AddFlags(rootCmd)
+	})
+
+	It("derives environment variable names", func() {
+		Expect(Name(rootCmd, "wait-timeout")).To(Equal("MY_APP_WAIT_TIMEOUT"))
+		Expect(Name(subCmd, "port")).To(Equal("MY_APP_PORT"))
+		SetPrefix(rootCmd, "FOO_")
+		Expect(Name(rootCmd, "wait-timeout")).To(Equal("FOO_WAIT_TIMEOUT"))
+	})
+
+	It("shows the environment variable names in the help", func() {
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs([]string{"serve", "--help"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(out.String()).To(And(
+			MatchRegexp(`--port int\s+port to serve on \[\$MY_APP_PORT\] \(default 42\)`),
+			MatchRegexp(`--debug\s+enables debugging \[\$MY_APP_DEBUG\]`),
+		))
+		clippy.AddFlags(rootCmd)
+		Expect(subCmd.Flags().Lookup("port").Usage).To(Equal("port to serve on [$MY_APP_PORT]"))
+	})
+
+	It("sets flags from the environment", func() {
+		setenv("MY_APP_DEBUG", "true")
+		setenv("MY_APP_TAGS", "bar,baz")
+		setenv("MY_APP_WAIT_TIMEOUT", "1m")
+		var provenance []clippy.FlagProvenance
+		rootCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+			provenance = clippy.Provenance(cmd)
+			return nil
+		}
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeTrue())
+		Expect(tags).To(ConsistOf("bar", "baz"))
+		Expect(timeout).To(Equal(time.Minute))
+		Expect(provenance).To(ContainElement(clippy.FlagProvenance{
+			Name: "wait-timeout", Value: "1m0s", Source: clippy.SourceEnv, Origin: "MY_APP_WAIT_TIMEOUT",
+		}))
+	})
+
+	It("sets inherited flags of sub commands from the environment", func() {
+		setenv("MY_APP_DEBUG", "true")
+		setenv("MY_APP_PORT", "666")
+		rootCmd.SetArgs([]string{"serve"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeTrue())
+		Expect(port).To(Equal(666))
+	})
+
+	It("gives command line flags precedence", func() {
+		setenv("MY_APP_TAGS", "bar,baz")
+		setenv("MY_APP_WAIT_TIMEOUT", "1m")
+		rootCmd.SetArgs([]string{"--tags", "xyz", "--wait-timeout", "1h"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(tags).To(ConsistOf("xyz"))
+		Expect(timeout).To(Equal(time.Hour))
+	})
+
+	It("gives mutually exclusive command line flags precedence", func() {
+		logLevel := rootCmd.PersistentFlags().String("log-level", "info", "")
+		rootCmd.MarkFlagsMutuallyExclusive("debug", "log-level")
+		setenv("MY_APP_LOG_LEVEL", "warn")
+		setenv("MY_APP_WAIT_TIMEOUT", "1m")
+		rootCmd.SetArgs([]string{"--debug"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeTrue())
+		Expect(*logLevel).To(Equal("info"))
+		Expect(timeout).To(Equal(time.Minute))
+	})
+
+	It("uses a custom prefix", func() {
+		setenv("MY_APP_DEBUG", "true")
+		setenv("FOO_WAIT_TIMEOUT", "1m")
+		SetPrefix(rootCmd, "FOO_")
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(debug).To(BeFalse())
+		Expect(timeout).To(Equal(time.Minute))
+	})
+
+	It("reports invalid environment variable values", func() {
+		setenv("MY_APP_DEBUG", "dunno")
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		Expect(rootCmd.Execute()).To(MatchError(
+			ContainSubstring(`invalid value "dunno" for environment variable MY_APP_DEBUG`)))
+	})
+
+})
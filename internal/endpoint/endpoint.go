@@ -0,0 +1,53 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+// Package endpoint serves HTTP handlers at TCP or unix socket addresses, such
+// as for the log level control, health, and status endpoints.
+package endpoint
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Serve the specified handler at the specified address in the background. The
+// address is either a host:port TCP address or a unix socket path prefixed by
+// "unix:". Serve returns a function to stop serving, which waits for the
+// server to have stopped.
+func Serve(addr string, handler http.Handler) (stop func() error, err error) {
+	network := "tcp"
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		network, addr = "unix", path
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = server.Serve(l)
+	}()
+	return func() error {
+		err := server.Close()
+		<-stopped
+		return err
+	}, nil
+}
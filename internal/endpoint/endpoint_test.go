@@ -0,0 +1,92 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package endpoint
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("serving endpoints", func() {
+
+	BeforeEach(func() {
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("hellorld!"))
+	})
+
+	get := func(client *http.Client, url string) string {
+		GinkgoHelper()
+		resp, err := client.Get(url)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return string(body)
+	}
+
+	It("serves at a TCP address", func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := l.Addr().String()
+		Expect(l.Close()).To(Succeed())
+
+		stop, err := Serve(addr, handler)
+		Expect(err).NotTo(HaveOccurred())
+		client := &http.Client{Transport: &http.Transport{}}
+		defer client.CloseIdleConnections()
+		Expect(get(client, "http://"+addr+"/")).To(Equal("hellorld!"))
+		client.CloseIdleConnections()
+		Expect(stop()).To(Succeed())
+	})
+
+	It("serves at a unix socket and removes the socket when stopped", func() {
+		sockPath := filepath.Join(GinkgoT().TempDir(), "endpoint.sock")
+		stop, err := Serve("unix:"+sockPath, handler)
+		Expect(err).NotTo(HaveOccurred())
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", sockPath)
+				},
+			},
+		}
+		defer client.CloseIdleConnections()
+		Expect(get(client, "http://endpoint/")).To(Equal("hellorld!"))
+		client.CloseIdleConnections()
+		Expect(stop()).To(Succeed())
+		Expect(sockPath).NotTo(BeAnExistingFile())
+	})
+
+	It("reports when it cannot listen", func() {
+		Expect(Serve("unix:"+filepath.Join(GinkgoT().TempDir(), "nada", "endpoint.sock"), handler)).
+			Error().To(HaveOccurred())
+	})
+
+})
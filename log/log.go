@@ -35,10 +35,11 @@ func init() {
 }
 
 // setupCLI runs after(!) the debug flag's setupCLI so that we can add our
-// "--log" flag and make it mutually exclusive to the "--debug" flag.
+// "--log" flag and make it mutually exclusive to the "--debug" and
+// "--log-level" flags.
 func setupCLI(cmd *cobra.Command) {
 	cmd.PersistentFlags().Bool(LogFlagName, false, "enables logging output")
-	cmd.MarkFlagsMutuallyExclusive(LogFlagName, debug.DebugFlagName)
+	cmd.MarkFlagsMutuallyExclusive(LogFlagName, debug.DebugFlagName, debug.LogLevelFlagName)
 	debug.SetDefaultLevel(cmd, slog.LevelError)
 }
 
@@ -46,7 +47,7 @@ func setupCLI(cmd *cobra.Command) {
 // logging bar when the "--log" flag has been specified with the command. It
 // does so by attaching the forced level to the context of the command.
 func beforeCommand(cmd *cobra.Command) error {
-	if log, _ := cmd.PersistentFlags().GetBool(LogFlagName); log {
+	if log, _ := cmd.Flags().GetBool(LogFlagName); log {
 		debug.SetLevel(cmd, slog.LevelInfo)
 	}
 	return nil
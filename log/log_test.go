@@ -75,4 +75,11 @@ var _ = Describe("info+ logging", func() {
 		Expect(output.String()).To(MatchRegexp(`(?s)msg="debug logging enabled".*level=DEBUG msg=\*debug\*`))
 	})
 
+	It("rejects log in combination with a log level", func() {
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		rootCmd.SetArgs([]string{"--" + LogFlagName, "--" + debug.LogLevelFlagName + "=info"})
+		Expect(rootCmd.Execute()).To(MatchError(ContainSubstring("none of the others can be")))
+	})
+
 })
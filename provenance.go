@@ -0,0 +1,164 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package clippy
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Source tells where the effective value of a flag came from.
+type Source string
+
+// The sources of effective flag values known to clippy. Plugins are free to
+// introduce their own sources.
+const (
+	SourceDefault     Source = "default"
+	SourceCommandLine Source = "command line"
+	SourceEnv         Source = "environment"
+	SourceConfig      Source = "configuration"
+)
+
+// FlagProvenance describes the effective value of a flag and where this value
+// came from.
+type FlagProvenance struct {
+	Name   string `json:"name"`             // name of the flag.
+	Value  string `json:"value"`            // effective value in textual representation.
+	Source Source `json:"source"`           // source of the effective value.
+	Origin string `json:"origin,omitempty"` // optional details, such as an env variable name.
+}
+
+// ctxKey "namespaces" the context keys this package uses internally for passing
+// information via contexts attached to cobra commands.
+type ctxKey int
+
+const (
+	ctxProvenance ctxKey = iota
+)
+
+// provenance records the sources of flag values, except for default values and
+// values from the command line.
+type provenance struct {
+	mu      sync.Mutex
+	sources map[string]FlagProvenance
+}
+
+// startProvenance attaches a new and empty provenance registry to the specified
+// command, replacing any existing registry.
+func startProvenance(cmd *cobra.Command) *provenance {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := &provenance{sources: map[string]FlagProvenance{}}
+	cmd.SetContext(context.WithValue(ctx, ctxProvenance, p))
+	return p
+}
+
+// provenanceOf returns the provenance registry attached to the specified
+// command, attaching a new registry if necessary.
+func provenanceOf(cmd *cobra.Command) *provenance {
+	if ctx := cmd.Context(); ctx != nil {
+		if p, ok := ctx.Value(ctxProvenance).(*provenance); ok {
+			return p
+		}
+	}
+	return startProvenance(cmd)
+}
+
+// SetSource records the source of the value of the named flag, together with
+// optional details about the origin, such as the name of an environment
+// variable or a configuration file position. Plugins setting flag values should
+// call SetSource after having successfully set a flag value.
+func SetSource(cmd *cobra.Command, flagName string, source Source, origin string) {
+	p := provenanceOf(cmd)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sources[flagName] = FlagProvenance{
+		Name:   flagName,
+		Source: source,
+		Origin: origin,
+	}
+}
+
+// Provenance returns the effective values of all flags of the specified
+// command, together with their sources, sorted by flag name. Flags that have
+// been changed without their source being recorded using [SetSource] are
+// considered to have been set on the command line.
+func Provenance(cmd *cobra.Command) []FlagProvenance {
+	p := provenanceOf(cmd)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var flags []FlagProvenance
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Name == "help" {
+			return
+		}
+		fp, ok := p.sources[flag.Name]
+		if !ok {
+			fp = FlagProvenance{Name: flag.Name, Source: SourceDefault}
+			if flag.Changed {
+				fp.Source = SourceCommandLine
+			}
+		}
+		fp.Value = flag.Value.String()
+		flags = append(flags, fp)
+	})
+	slices.SortFunc(flags, func(a, b FlagProvenance) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return flags
+}
+
+// mutuallyExclusiveAnnotation is the flag annotation cobra uses for recording
+// the mutually exclusive flag groups a flag belongs to.
+const mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+
+// MutuallyExcluded returns true if another flag in one of the mutually
+// exclusive flag groups of the named flag has already been set from a source
+// other than the specified source, such as on the command line. Plugins
+// setting flag values from other sources should then leave the named flag
+// alone, as otherwise cobra's flag group check fails the command instead of
+// giving the other flag precedence.
+func MutuallyExcluded(cmd *cobra.Command, flagName string, source Source) bool {
+	flags := cmd.Flags()
+	flag := flags.Lookup(flagName)
+	if flag == nil {
+		return false
+	}
+	p := provenanceOf(cmd)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, group := range flag.Annotations[mutuallyExclusiveAnnotation] {
+		for _, name := range strings.Fields(group) {
+			if name == flagName {
+				continue
+			}
+			other := flags.Lookup(name)
+			if other == nil || !other.Changed {
+				continue
+			}
+			if fp, ok := p.sources[name]; !ok || fp.Source != source {
+				return true
+			}
+		}
+	}
+	return false
+}
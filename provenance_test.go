@@ -0,0 +1,99 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package clippy
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("flag provenance", func() {
+
+	It("tracks the sources of effective flag values", func() {
+		group := plugger.Group[cliplugin.BeforeCommand]()
+		stash := group.Backup()
+		DeferCleanup(func() { group.Restore(stash) })
+		group.Clear()
+		group.Register(func(cmd *cobra.Command) error {
+			_ = cmd.Flags().Set("bar", "42")
+			SetSource(cmd, "bar", SourceEnv, "FOO_BAR")
+			return nil
+		})
+
+		var provenance []FlagProvenance
+		rootCmd := &cobra.Command{
+			Use: "foo",
+			PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+				return BeforeCommand(cmd)
+			},
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				provenance = Provenance(cmd)
+				return nil
+			},
+		}
+		rootCmd.Flags().SortFlags = false
+		rootCmd.Flags().Int("zoo", 1, "")
+		rootCmd.Flags().Int("bar", 1, "")
+		rootCmd.PersistentFlags().String("baz", "", "")
+		rootCmd.SetArgs([]string{"--baz", "xyz"})
+		Expect(rootCmd.Execute()).To(Succeed())
+		Expect(provenance).To(HaveExactElements(
+			FlagProvenance{Name: "bar", Value: "42", Source: SourceEnv, Origin: "FOO_BAR"},
+			FlagProvenance{Name: "baz", Value: "xyz", Source: SourceCommandLine},
+			FlagProvenance{Name: "zoo", Value: "1", Source: SourceDefault},
+		))
+	})
+
+	It("starts with a fresh registry", func() {
+		group := plugger.Group[cliplugin.BeforeCommand]()
+		stash := group.Backup()
+		DeferCleanup(func() { group.Restore(stash) })
+		group.Clear()
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Int("foo", 1, "")
+		SetSource(cmd, "foo", SourceConfig, "")
+		Expect(Provenance(cmd)).To(ConsistOf(HaveField("Source", SourceConfig)))
+		Expect(BeforeCommandAll(cmd)).To(Succeed())
+		Expect(Provenance(cmd)).To(ConsistOf(HaveField("Source", SourceDefault)))
+	})
+
+	It("tells when flags are excluded by mutually exclusive flags already set", func() {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("foo", false, "")
+		cmd.Flags().Bool("bar", false, "")
+		cmd.Flags().Bool("baz", false, "")
+		cmd.MarkFlagsMutuallyExclusive("foo", "bar")
+		Expect(MutuallyExcluded(cmd, "nada", SourceEnv)).To(BeFalse())
+		Expect(MutuallyExcluded(cmd, "foo", SourceEnv)).To(BeFalse())
+
+		Expect(cmd.Flags().Set("baz", "true")).To(Succeed())
+		Expect(MutuallyExcluded(cmd, "foo", SourceEnv)).To(BeFalse())
+
+		Expect(cmd.Flags().Set("bar", "true")).To(Succeed())
+		SetSource(cmd, "bar", SourceEnv, "BAR")
+		Expect(MutuallyExcluded(cmd, "foo", SourceEnv)).To(BeFalse())
+		Expect(MutuallyExcluded(cmd, "foo", SourceConfig)).To(BeTrue())
+		Expect(MutuallyExcluded(cmd, "bar", SourceEnv)).To(BeFalse())
+
+		startProvenance(cmd)
+		Expect(MutuallyExcluded(cmd, "foo", SourceEnv)).To(BeTrue())
+	})
+
+})
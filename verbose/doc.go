@@ -0,0 +1,28 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+/*
+Package verbose supplies the "-v" (“--verbose”) count and "-q" (“--quiet”) CLI
+flags for configuring the default structured logger, as an alternative to the
+"--log" flag of the [github.com/thediveo/clippy/log] package.
+
+By default, only warnings and errors get logged. Each "-v" increases the
+verbosity: "-v" logs information, "-vv" debug records, and "-vvv" trace
+records. "-q" logs only errors. These flags are mutually exclusive with the
+"--debug" and "--log-level" flags of the [github.com/thediveo/clippy/debug]
+package, as well as with the "--log" flag if present. When used together with
+the log package, the default of logging warnings and errors takes precedence
+over the log package's default.
+*/
+package verbose
@@ -0,0 +1,82 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package verbose
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/clippy/debug"
+	"github.com/thediveo/go-plugger/v3"
+)
+
+const (
+	VerboseFlagName = "verbose"
+	QuietFlagName   = "quiet"
+)
+
+func init() {
+	plugger.Group[cliplugin.SetupCLI]().Register(
+		setupCLI, plugger.WithPlugin("clippy/verbose"), plugger.WithPlacement(">clippy/log"))
+	plugger.Group[cliplugin.BeforeCommand]().Register(
+		beforeCommand, plugger.WithPlugin("clippy/verbose"), plugger.WithPlacement("<clippy/debug"))
+}
+
+// setupCLI runs after(!) the debug flag's setupCLI as well as the log flag's
+// setupCLI so that we can add our "-v" and "-q" flags and make them mutually
+// exclusive to the "--debug" and "--log-level" flags, as well as to the "--log"
+// flag if present. If the log plugin isn't present, our plugin name still sorts
+// after the debug plugin. Running last also makes our default level win over
+// the log plugin's default level.
+func setupCLI(cmd *cobra.Command) {
+	cmd.PersistentFlags().CountP(VerboseFlagName, "v",
+		"increases logging verbosity: -v info, -vv debug, -vvv trace")
+	cmd.PersistentFlags().BoolP(QuietFlagName, "q", false, "logs only errors")
+	exclusive := []string{debug.DebugFlagName, debug.LogLevelFlagName}
+	if cmd.PersistentFlags().Lookup("log") != nil {
+		exclusive = append(exclusive, "log")
+	}
+	cmd.MarkFlagsMutuallyExclusive(append([]string{VerboseFlagName, QuietFlagName}, exclusive...)...)
+	debug.SetDefaultLevel(cmd, slog.LevelWarn)
+}
+
+// beforeCommand runs before(!) the debug flag's beforeCommand and adjusts the
+// logging bar when the "-v" or "-q" flags have been specified with the
+// command. It does so by attaching the forced level to the context of the
+// command.
+func beforeCommand(cmd *cobra.Command) error {
+	if quiet, _ := cmd.Flags().GetBool(QuietFlagName); quiet {
+		debug.SetLevel(cmd, slog.LevelError)
+	} else if verbosity, _ := cmd.Flags().GetCount(VerboseFlagName); verbosity > 0 {
+		debug.SetLevel(cmd, Level(verbosity))
+	}
+	return nil
+}
+
+// Level returns the log level for the specified verbosity, that is, the number
+// of "-v" flags: warn for 0, info for 1, debug for 2, and trace for 3 or more.
+func Level(verbosity int) slog.Level {
+	switch {
+	case verbosity <= 0:
+		return slog.LevelWarn
+	case verbosity == 1:
+		return slog.LevelInfo
+	case verbosity == 2:
+		return slog.LevelDebug
+	default:
+		return debug.LevelTrace
+	}
+}
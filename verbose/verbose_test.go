@@ -0,0 +1,118 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package verbose
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/thediveo/clippy"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/clippy/debug"
+	"github.com/thediveo/clippy/log"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("verbosity logging", func() {
+
+	var rootCmd *cobra.Command
+	var output bytes.Buffer
+
+	BeforeEach(func() {
+		oldLogger := slog.Default()
+		DeferCleanup(func() {
+			slog.SetDefault(oldLogger)
+		})
+
+		output.Reset()
+
+		rootCmd = &cobra.Command{
+			PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+				return clippy.BeforeCommand(cmd)
+			},
+			RunE: func(*cobra.Command, []string) error { return nil },
+		}
+		debug.SetWriter(rootCmd, &output)
+	})
+
+	DescribeTable("mapping verbosity to levels",
+		func(verbosity int, expected slog.Level) {
+			Expect(Level(verbosity)).To(Equal(expected))
+		},
+		Entry(nil, -1, slog.LevelWarn),
+		Entry(nil, 0, slog.LevelWarn),
+		Entry(nil, 1, slog.LevelInfo),
+		Entry(nil, 2, slog.LevelDebug),
+		Entry(nil, 3, debug.LevelTrace),
+		Entry(nil, 42, debug.LevelTrace),
+	)
+
+	It("defaults to logging only warnings or worse", func() {
+		clippy.AddFlags(rootCmd)
+		Expect(rootCmd.Execute()).To(Succeed())
+		slog.Info("*information*")
+		Expect(output.String()).To(BeEmpty())
+		slog.Warn("*warning*")
+		Expect(output.String()).To(MatchRegexp(`level=WARN msg=\*warning\*`))
+	})
+
+	DescribeTable("increasing verbosity",
+		func(args []string, level slog.Level) {
+			clippy.AddFlags(rootCmd)
+			rootCmd.SetArgs(args)
+			Expect(rootCmd.Execute()).To(Succeed())
+			slog.Log(context.Background(), level-1, "*below*")
+			Expect(output.String()).NotTo(ContainSubstring("*below*"))
+			slog.Log(context.Background(), level, "*at*")
+			Expect(output.String()).To(ContainSubstring("*at*"))
+		},
+		Entry(nil, []string{"-v"}, slog.LevelInfo),
+		Entry(nil, []string{"-vv"}, slog.LevelDebug),
+		Entry(nil, []string{"-v", "-v", "-v"}, debug.LevelTrace),
+		Entry(nil, []string{"--" + VerboseFlagName}, slog.LevelInfo),
+		Entry(nil, []string{"-q"}, slog.LevelError),
+	)
+
+	It("sets up its flags after the debug and log plugins", func() {
+		Expect(plugger.Group[cliplugin.SetupCLI]().Plugins()).To(HaveExactElements(
+			"clippy/debug", "clippy/log", "clippy/verbose"))
+	})
+
+	It("rejects verbosity in combination with other logging level flags", func() {
+		clippy.AddFlags(rootCmd)
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		for _, args := range [][]string{
+			{"-v", "-q"},
+			{"-v", "--" + debug.DebugFlagName},
+			{"-q", "--" + debug.LogLevelFlagName + "=info"},
+			{"-vv", "--" + log.LogFlagName},
+		} {
+			rootCmd.SetArgs(args)
+			Expect(rootCmd.Execute()).To(MatchError(ContainSubstring("none of the others can be")), "%v", args)
+			rootCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+				_ = flag.Value.Set(flag.DefValue)
+				flag.Changed = false
+			})
+		}
+	})
+
+})
@@ -0,0 +1,237 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/thediveo/go-plugger/v3"
+)
+
+// Needs defines an exposed plugin symbol type for declaring the names of the
+// workers the [Do] work function exposed by the same (named) plugin depends
+// on. [DoAll] starts a worker only after all the workers it needs have become
+// ready, and cancels a worker only after all workers depending on it have
+// returned.
+type Needs func() []string
+
+// worker is a Do work function together with its dependencies.
+type worker struct {
+	name         string
+	fn           Do
+	needs        []*worker // workers this worker depends on.
+	dependents   []*worker // workers depending on this worker.
+	phase        ShutdownPhase
+	shutdown     <-chan struct{} // closed when the worker's shutdown phase starts.
+	shutdownOver <-chan struct{} // closed when the worker's shutdown phase is over or timed out.
+
+	readyMu sync.Mutex
+	ready   chan struct{} // closed when ready; replaced when restarting.
+	done    chan struct{} // closed after the worker has returned.
+
+	status workerStatus
+}
+
+// markReady marks the worker as ready; it is safe to mark a worker as ready
+// multiple times.
+func (w *worker) markReady() {
+	w.readyMu.Lock()
+	select {
+	case <-w.ready:
+	default:
+		close(w.ready)
+	}
+	w.readyMu.Unlock()
+	w.status.setState(StateReady)
+}
+
+// unready resets the readiness of the worker when it is going to be restarted,
+// so that it needs to signal its readiness again.
+func (w *worker) unready() {
+	w.readyMu.Lock()
+	defer w.readyMu.Unlock()
+	select {
+	case <-w.ready:
+		w.ready = make(chan struct{})
+	default:
+	}
+}
+
+// readyChan returns a channel that gets closed when the worker is ready.
+func (w *worker) readyChan() <-chan struct{} {
+	w.readyMu.Lock()
+	defer w.readyMu.Unlock()
+	return w.ready
+}
+
+// awaitNeeds waits for all workers needed by this worker to become ready,
+// returning true. If the specified context is done before, it returns false.
+func (w *worker) awaitNeeds(ctx context.Context) bool {
+	for _, need := range w.needs {
+		select {
+		case <-need.readyChan():
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// Ready signals that the worker the specified context was passed to is ready,
+// so that workers depending on it can start. A worker that returns nil counts
+// as ready, even if it didn't call Ready before. Ready does nothing if the
+// context wasn't passed to a Do work function by [DoAll].
+func Ready(ctx context.Context) {
+	if w := workerOf(ctx); w != nil {
+		w.markReady()
+	}
+}
+
+// Readiness returns the readiness of all workers run by the same [DoAll] as the
+// worker the specified context was passed to, by worker names. Workers sharing
+// the same name are ready only if all of them are ready. Workers being
+// restarted aren't ready until they signal their readiness again. Readiness returns nil
+// if the context wasn't passed to a Do work function by DoAll.
+func Readiness(ctx context.Context) map[string]bool {
+	workers, ok := ctx.Value(ctxWorkers).([]*worker)
+	if !ok {
+		return nil
+	}
+	readiness := make(map[string]bool, len(workers))
+	for _, w := range workers {
+		ready, ok := readiness[w.name]
+		if !ok {
+			ready = true
+		}
+		select {
+		case <-w.readyChan():
+		default:
+			ready = false
+		}
+		readiness[w.name] = ready
+	}
+	return readiness
+}
+
+// newWorkers returns the workers for the specified Do work function symbols,
+// with their dependencies as declared by Needs symbols resolved, and assigned
+// to their shutdown phases. It returns an error if a worker needs an unknown
+// worker, if there is a dependency cycle, or if the shutdown phases are
+// invalid.
+func newWorkers(plugs []plugger.Symbol[Do]) ([]*worker, error) {
+	workers := make([]*worker, 0, len(plugs))
+	byName := map[string][]*worker{}
+	for _, plug := range plugs {
+		w := &worker{
+			name:   plug.Plugin,
+			fn:     plug.S,
+			ready:  make(chan struct{}),
+			done:   make(chan struct{}),
+			status: workerStatus{state: StateStarting},
+		}
+		workers = append(workers, w)
+		byName[w.name] = append(byName[w.name], w)
+	}
+	needsGroup := plugger.Group[Needs]()
+	for _, w := range workers {
+		needs := needsGroup.PluginSymbol(w.name)
+		if needs == nil {
+			continue
+		}
+		for _, name := range needs() {
+			needed, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("worker %q needs unknown worker %q", w.name, name)
+			}
+			for _, need := range needed {
+				w.needs = append(w.needs, need)
+				need.dependents = append(need.dependents, w)
+			}
+		}
+	}
+	if cycle := findCycle(workers); cycle != nil {
+		names := make([]string, 0, len(cycle))
+		for _, w := range cycle {
+			names = append(names, strconv.Quote(w.name))
+		}
+		return nil, fmt.Errorf("dependency cycle between workers %s", strings.Join(names, " -> "))
+	}
+	if err := assignPhases(workers); err != nil {
+		return nil, err
+	}
+	return workers, nil
+}
+
+// findCycle returns the workers forming a dependency cycle, with the first
+// worker repeated at the end, or nil if there is no cycle.
+func findCycle(workers []*worker) []*worker {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*worker]int{}
+	var path []*worker
+	var visit func(w *worker) []*worker
+	visit = func(w *worker) []*worker {
+		switch state[w] {
+		case visited:
+			return nil
+		case visiting:
+			for idx, pw := range path {
+				if pw == w {
+					return append(path[idx:], w)
+				}
+			}
+		}
+		state[w] = visiting
+		path = append(path, w)
+		for _, need := range w.needs {
+			if cycle := visit(need); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[w] = visited
+		return nil
+	}
+	for _, w := range workers {
+		if cycle := visit(w); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// stopAfterDependents calls the specified stop function after the specified
+// context is done, the worker's shutdown phase has started, and all workers
+// depending on the specified worker have returned. Dependents still running
+// when their shutdown phase times out don't block stopping the worker.
+func stopAfterDependents(ctx context.Context, w *worker, stop context.CancelFunc) {
+	<-ctx.Done()
+	<-w.shutdown
+	for _, dependent := range w.dependents {
+		select {
+		case <-dependent.done:
+		case <-dependent.shutdownOver:
+		}
+	}
+	w.status.setState(StateStopping)
+	stop()
+}
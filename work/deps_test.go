@@ -0,0 +1,251 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("worker dependencies", func() {
+
+	var group = plugger.Group[Do]()
+	var needs = plugger.Group[Needs]()
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+		slog.SetDefault(slog.New(slog.NewTextHandler(gbytes.NewBuffer(), nil)))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+		group.Clear()
+		oldNeeds := needs.Backup()
+		DeferCleanup(func() { needs.Restore(oldNeeds) })
+		needs.Clear()
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	// need registers the specified worker as needing the other named workers.
+	need := func(name string, others ...string) {
+		needs.Register(func() []string { return others }, plugger.WithPlugin(name))
+	}
+
+	It("does nothing when signalling readiness outside a worker", func() {
+		Expect(func() { Ready(context.Background()) }).NotTo(Panic())
+		Expect(Readiness(context.Background())).To(BeNil())
+	})
+
+	It("reports the readiness of all workers", func() {
+		readiness := make(chan map[string]bool, 2)
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("a"))
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			readiness <- Readiness(ctx)
+			Ready(ctx)
+			readiness <- Readiness(ctx)
+			return errors.New("foo!")
+		}, plugger.WithPlugin("b"))
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			Ready(ctx)
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("b"))
+
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(HaveOccurred())
+		Expect(<-readiness).To(Equal(map[string]bool{"a": false, "b": false}))
+		Expect(<-readiness).To(HaveKeyWithValue("a", false))
+	})
+
+	It("resets the readiness of restarting workers", func() {
+		restarts := plugger.Group[Restart]()
+		oldRestarts := restarts.Backup()
+		DeferCleanup(func() { restarts.Restore(oldRestarts) })
+		restarts.Clear()
+
+		var attempts atomic.Int32
+		fail := make(chan struct{})
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			Ready(ctx)
+			if attempts.Add(1) == 1 {
+				<-fail
+				return errors.New("db connection lost")
+			}
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("db"))
+		restarts.Register(func() RestartPolicy {
+			return RestartPolicy{Mode: RestartOnFailure, InitialBackoff: 200 * time.Millisecond}
+		}, plugger.WithPlugin("db"))
+		probes := make(chan context.Context, 1)
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			probes <- ctx
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("probe"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errch := make(chan error, 1)
+		go func() { errch <- DoAll(ctx, &cobra.Command{}) }()
+
+		var probe context.Context
+		Eventually(probes).Within(2 * time.Second).Should(Receive(&probe))
+		readiness := func() map[string]bool { return Readiness(probe) }
+		Eventually(readiness).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).
+			Should(HaveKeyWithValue("db", true))
+		close(fail)
+		Eventually(readiness).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).
+			Should(HaveKeyWithValue("db", false))
+		Expect(attempts.Load()).To(Equal(int32(1)))
+		Eventually(readiness).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).
+			Should(HaveKeyWithValue("db", true))
+		Expect(attempts.Load()).To(Equal(int32(2)))
+		cancel()
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+	})
+
+	It("starts dependents only after the workers they need are ready", func() {
+		var dbReady atomic.Bool
+		httpStarted := make(chan bool, 1)
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			time.Sleep(50 * time.Millisecond)
+			dbReady.Store(true)
+			Ready(ctx)
+			Ready(ctx)
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("db"))
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			httpStarted <- dbReady.Load()
+			return nil
+		}, plugger.WithPlugin("http"))
+		need("http", "db")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Eventually(httpStarted).Within(2 * time.Second).Should(Receive(BeTrue()))
+			cancel()
+		}()
+		Expect(DoAll(ctx, &cobra.Command{})).To(Succeed())
+	})
+
+	It("counts workers returning nil as ready", func() {
+		var migrated atomic.Bool
+		group.Register(func(context.Context, *cobra.Command) error {
+			migrated.Store(true)
+			return nil
+		}, plugger.WithPlugin("migrator"))
+		group.Register(func(context.Context, *cobra.Command) error {
+			if !migrated.Load() {
+				return errors.New("not migrated")
+			}
+			return nil
+		}, plugger.WithPlugin("http"))
+		need("http", "migrator")
+
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(Succeed())
+	})
+
+	It("doesn't start dependents of failed workers", func() {
+		var started atomic.Bool
+		group.Register(func(context.Context, *cobra.Command) error {
+			return errors.New("foo!")
+		}, plugger.WithPlugin("migrator"))
+		group.Register(func(context.Context, *cobra.Command) error {
+			started.Store(true)
+			return nil
+		}, plugger.WithPlugin("http"))
+		need("http", "migrator")
+
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(`worker "migrator": foo!`))
+		Expect(started.Load()).To(BeFalse())
+	})
+
+	It("shuts down in reverse dependency order", func() {
+		var httpDone atomic.Bool
+		dbCancelledAfterHttp := make(chan bool, 1)
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			Ready(ctx)
+			<-ctx.Done()
+			dbCancelledAfterHttp <- httpDone.Load()
+			return nil
+		}, plugger.WithPlugin("db"))
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			httpDone.Store(true)
+			return nil
+		}, plugger.WithPlugin("http"))
+		need("http", "db")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			cancel()
+		}()
+		Expect(DoAll(ctx, &cobra.Command{})).To(Succeed())
+		Expect(dbCancelledAfterHttp).To(Receive(BeTrue()))
+	})
+
+	It("rejects unknown dependencies", func() {
+		group.Register(func(context.Context, *cobra.Command) error { return nil },
+			plugger.WithPlugin("http"))
+		need("http", "db")
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(
+			`worker "http" needs unknown worker "db"`))
+	})
+
+	It("rejects dependency cycles", func() {
+		for _, name := range []string{"a", "b", "c"} {
+			group.Register(func(context.Context, *cobra.Command) error { return nil },
+				plugger.WithPlugin(name))
+		}
+		need("a", "b")
+		need("b", "c")
+		need("c", "b")
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(
+			`dependency cycle between workers "b" -> "c" -> "b"`))
+	})
+
+	It("rejects self-dependencies", func() {
+		group.Register(func(context.Context, *cobra.Command) error { return nil },
+			plugger.WithPlugin("a"))
+		need("a", "a")
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(
+			`dependency cycle between workers "a" -> "a"`))
+	})
+
+})
@@ -11,5 +11,76 @@ If a work function encounters problems either early on or later and before the
 passed context is cancelled/done, it should simply return an error. All
 remaining work functions still standing will then see their passed context
 cancelled and should more or less gracefully wind down without returning errors.
+
+Each work function runs as a named worker, with the worker's name being the
+plugin name of the work function. Work functions can retrieve their name from
+the passed context using [Name], and log using a logger from [Logger] that
+adds the worker's name to all records. The errors returned by work functions
+get wrapped with the names of their workers.
+
+When a work function panics, [DoAll] recovers and converts the panic into a
+[PanicError] carrying the worker's name and stack trace, logs it, and then
+winds down the remaining workers as with any other failure. Applications
+preferring to crash instead can opt out using [WithCrashOnPanic].
+
+Plugins can additionally expose a [Restart] symbol alongside their Do work
+function in order to declare a [RestartPolicy] for their worker: never
+restarting (the default), restarting only on failure, or always restarting,
+with exponential backoff between restarts. Exceeding the maximum number of
+restarts within a time window escalates the worker's error to the whole group.
+
+	func init() {
+		plugger.Group[work.Do]().Register(collect, plugger.WithPlugin("collector"))
+		plugger.Group[work.Restart]().Register(func() work.RestartPolicy {
+			return work.RestartPolicy{
+				Mode:        work.RestartOnFailure,
+				MaxRestarts: 5,
+				Window:      time.Minute,
+			}
+		}, plugger.WithPlugin("collector"))
+	}
+
+Workers can depend on other workers by exposing a [Needs] symbol alongside
+their Do work function, returning the names of the workers they need. A worker
+then starts only after the workers it needs have become ready, which they
+signal by calling [Ready] (or by returning nil). When winding down, workers get
+cancelled in reverse dependency order, that is, a worker gets cancelled only
+after all workers depending on it have returned. [Readiness] reports the
+readiness of all workers, such as for the readiness endpoint served by the
+[github.com/thediveo/clippy/work/health] package. Similarly, workers report
+their health using [SetHealth], and [Health] reports the unhealthy workers.
+
+	func init() {
+		plugger.Group[work.Do]().Register(serve, plugger.WithPlugin("http"))
+		plugger.Group[work.Needs]().Register(func() []string {
+			return []string{"db-migrator"}
+		}, plugger.WithPlugin("http"))
+	}
+
+Cancelling all workers at once might cut off in-flight work. Plugins thus can
+expose a [Phase] symbol alongside their Do work function, assigning their
+worker to a [ShutdownPhase]: [PhaseIngress] for workers accepting new work,
+[PhaseDrain] (the default) for workers finishing in-flight work, and
+[PhaseBackend] for workers providing backends. [DoAll] cancels the workers
+phase by phase, waiting for the workers of each phase to return before
+proceeding with the next phase. [WithPhaseTimeout] limits how long to wait for
+each phase. A worker must not need workers from earlier shutdown phases.
+
+Misbehaving work functions might not return after their context has been
+cancelled. [WithShutdownTimeout] limits the grace period for winding down,
+after which [DoAll] returns an error listing the workers still running. When
+debug logging is enabled, DoAll additionally logs the goroutine stacks of these
+workers. The [github.com/thediveo/clippy/work/shutdown] package supplies a
+“--shutdown-timeout” CLI flag for setting the grace period.
+
+[Status] reports the state of each worker of the currently running [DoAll]
+calls, together with its start time, restart count, and last error. The
+[github.com/thediveo/clippy/work/status] package serves this status as JSON on
+a local unix socket or HTTP endpoint for live introspection.
+
+Instead of deriving a context cancelled upon SIGINT or SIGTERM and then calling
+[DoAll], commands can simply call [Run]. Run logs the signal received and
+force-exits upon a second signal with [ForcedExitCode]. Optionally, Run calls
+reload callbacks registered by workers using [OnReload] upon SIGHUP.
 */
 package work
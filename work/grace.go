@@ -0,0 +1,138 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"runtime/pprof"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// WithShutdownTimeout sets the grace period for the workers to return after
+// the context passed to them has been cancelled. After the grace period, DoAll
+// returns an error listing the workers still running instead of waiting
+// indefinitely. A shutdown timeout set using [SetShutdownTimeout], such as by
+// the “--shutdown-timeout” flag, takes precedence.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.shutdownTimeout = d
+	}
+}
+
+// SetShutdownTimeout sets the grace period for the workers to return after the
+// context passed to them has been cancelled, overriding [WithShutdownTimeout].
+func SetShutdownTimeout(cmd *cobra.Command, d time.Duration) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.SetContext(context.WithValue(ctx, ctxShutdownTimeout, d))
+}
+
+// running keeps track of the workers that haven't returned yet, as well as the
+// earliest error returned by a worker.
+type running struct {
+	mu      sync.Mutex
+	workers map[int]string // names of running workers, by index.
+	err     error
+}
+
+func newRunning() *running {
+	return &running{workers: map[int]string{}}
+}
+
+func (r *running) start(idx int, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workers[idx] = name
+}
+
+func (r *running) done(idx int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.workers, idx)
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// pending returns the names of the workers still running, in their order of
+// starting, as well as the earliest error returned by a worker.
+func (r *running) pending() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var names []string
+	for _, idx := range slices.Sorted(maps.Keys(r.workers)) {
+		names = append(names, strconv.Quote(r.workers[idx]))
+	}
+	return names, r.err
+}
+
+// wait for the workers in the specified group to return. After the specified
+// context is done and the workers haven't returned within the specified
+// shutdown timeout (unless zero), wait gives up and returns an error listing
+// the workers still running.
+func wait(ctx context.Context, group *errgroup.Group, workers *running, timeout time.Duration) error {
+	if timeout <= 0 {
+		return group.Wait()
+	}
+	done := make(chan error, 1)
+	go func() { done <- group.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+	names, err := workers.pending()
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		slog.Debug("workers still running", slog.String("stacks", workerStacks()))
+	}
+	return errors.Join(err, fmt.Errorf("shutdown timeout of %s exceeded, workers still running: %s",
+		timeout, strings.Join(names, ", ")))
+}
+
+// workerStacks returns the stacks of the goroutines of workers, as identified by
+// their profiler labels.
+func workerStacks() string {
+	var buf bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	var stacks []string
+	label := strconv.Quote(WorkerKey) + ":"
+	for _, stack := range strings.Split(buf.String(), "\n\n") {
+		if strings.Contains(stack, label) {
+			stacks = append(stacks, stack)
+		}
+	}
+	return strings.Join(stacks, "\n\n")
+}
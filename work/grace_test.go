@@ -0,0 +1,112 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("graceful shutdown", func() {
+
+	var output *gbytes.Buffer
+	var group = plugger.Group[Do]()
+	var stuck Do
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+
+		output = gbytes.NewBuffer()
+		slog.SetDefault(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+		group.Clear()
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		release := make(chan struct{})
+		DeferCleanup(func() { close(release) })
+		stuck = func(context.Context, *cobra.Command) error {
+			<-release
+			return nil
+		}
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("good"))
+	})
+
+	It("gives up on workers not returning in time", func() {
+		group.Register(stuck, plugger.WithPlugin("stuck"))
+		group.Register(stuck, plugger.WithPlugin("another stuck"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(DoAll(ctx, &cobra.Command{}, WithShutdownTimeout(10*time.Millisecond))).To(MatchError(
+			`shutdown timeout of 10ms exceeded, workers still running: "another stuck", "stuck"`))
+		Expect(string(output.Contents())).To(MatchRegexp(`level=DEBUG msg="workers still running" stacks=".*labels: {\\"worker\\":\\"stuck\\"}`))
+		Expect(string(output.Contents())).NotTo(ContainSubstring(`\"worker\":\"good\"`))
+	})
+
+	It("reports the error of a failed worker too", func() {
+		group.Register(stuck, plugger.WithPlugin("stuck"))
+		group.Register(func(context.Context, *cobra.Command) error {
+			return errors.New("foo!")
+		}, plugger.WithPlugin("failed"))
+
+		err := DoAll(context.Background(), &cobra.Command{}, WithShutdownTimeout(10*time.Millisecond))
+		Expect(err).To(MatchError(ContainSubstring(`worker "failed": foo!`)))
+		Expect(err).To(MatchError(ContainSubstring(`workers still running: "stuck"`)))
+	})
+
+	It("returns when all workers have returned in time", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(DoAll(ctx, &cobra.Command{}, WithShutdownTimeout(time.Hour))).To(Succeed())
+	})
+
+	It("returns when all workers have returned before cancellation", func() {
+		group.Clear()
+		Expect(DoAll(context.Background(), &cobra.Command{}, WithShutdownTimeout(time.Hour))).To(Succeed())
+	})
+
+	It("gives the command's shutdown timeout precedence", func() {
+		group.Register(stuck, plugger.WithPlugin("stuck"))
+		cmd := &cobra.Command{}
+		SetShutdownTimeout(cmd, 10*time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(DoAll(ctx, cmd, WithShutdownTimeout(time.Hour))).To(MatchError(
+			ContainSubstring("shutdown timeout of 10ms exceeded")))
+	})
+
+})
@@ -0,0 +1,33 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+/*
+Package health supplies a [work.Do] plugin serving “/healthz” and “/readyz”
+HTTP endpoints for services based on [work.DoAll], at the address specified
+using the “--health-addr” CLI flag. The address is either a TCP host:port
+address, or a unix socket path prefixed with “unix:”. Without this flag, no
+endpoints are served.
+
+Workers report their health using [work.SetHealth] with the context passed to
+them; workers not reporting are considered healthy. The readiness of workers is
+signalled using [work.Ready]. The endpoints respond with status 200 if all
+workers are healthy or ready, respectively, and status 503 otherwise. When
+requested using the “verbose” query parameter or when accepting
+“application/json”, the endpoints return a [Response] in JSON format detailing
+the individual workers.
+
+The health worker belongs to the [work.PhaseBackend] shutdown phase, so that it
+keeps serving while other workers are winding down.
+*/
+package health
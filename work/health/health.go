@@ -0,0 +1,158 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/clippy/internal/endpoint"
+	"github.com/thediveo/clippy/work"
+	"github.com/thediveo/go-plugger/v3"
+)
+
+const (
+	HealthAddrFlagName = "health-addr"
+)
+
+// Paths of the health and readiness endpoints.
+const (
+	HealthzPath = "/healthz"
+	ReadyzPath  = "/readyz"
+)
+
+func init() {
+	plugger.Group[cliplugin.SetupCLI]().Register(
+		setupCLI, plugger.WithPlugin("clippy/work/health"))
+	plugger.Group[work.Do]().Register(
+		serve, plugger.WithPlugin("clippy/work/health"))
+	plugger.Group[work.Phase]().Register(
+		func() work.ShutdownPhase { return work.PhaseBackend }, plugger.WithPlugin("clippy/work/health"))
+}
+
+// setupCLI adds the "--health-addr" flag to the specified command.
+func setupCLI(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(HealthAddrFlagName, "",
+		"serves "+HealthzPath+" and "+ReadyzPath+" HTTP endpoints at the specified host:port or unix:path")
+}
+
+// Response details the health or readiness of the individual workers.
+type Response struct {
+	Status string  `json:"status"` // "ok" or "failed".
+	Checks []Check `json:"checks"` // sorted by worker names.
+}
+
+// Check is the health or readiness of an individual worker.
+type Check struct {
+	Worker string `json:"worker"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+}
+
+// serve the health and readiness endpoints at the address specified by the
+// "--health-addr" flag until the context is done. If no address was specified,
+// serve immediately returns.
+func serve(ctx context.Context, cmd *cobra.Command) error {
+	addr, _ := cmd.Flags().GetString(HealthAddrFlagName)
+	if addr == "" {
+		return nil
+	}
+	stop, err := endpoint.Serve(addr, newHandler(ctx))
+	if err != nil {
+		return err
+	}
+	work.Ready(ctx)
+	<-ctx.Done()
+	return stop()
+}
+
+// newHandler returns the handler serving the health and readiness endpoints
+// for the workers run by the same [work.DoAll] as the worker the specified
+// context was passed to.
+func newHandler(ctx context.Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(HealthzPath, func(w http.ResponseWriter, req *http.Request) {
+		respond(w, req, healthChecks(ctx))
+	})
+	mux.HandleFunc(ReadyzPath, func(w http.ResponseWriter, req *http.Request) {
+		respond(w, req, readyChecks(ctx))
+	})
+	return mux
+}
+
+// healthChecks returns the health of all workers, sorted by worker names.
+func healthChecks(ctx context.Context) []Check {
+	readiness := work.Readiness(ctx)
+	errs := work.Health(ctx)
+	checks := make([]Check, 0, len(readiness))
+	for _, name := range slices.Sorted(maps.Keys(readiness)) {
+		check := Check{Worker: name, OK: true}
+		if err := errs[name]; err != nil {
+			check.OK = false
+			check.Error = err.Error()
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// readyChecks returns the readiness of all workers, sorted by worker names.
+func readyChecks(ctx context.Context) []Check {
+	readiness := work.Readiness(ctx)
+	checks := make([]Check, 0, len(readiness))
+	for _, name := range slices.Sorted(maps.Keys(readiness)) {
+		check := Check{Worker: name, OK: readiness[name]}
+		if !check.OK {
+			check.Error = "not ready"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// respond with status 200 if all checks are ok, and 503 otherwise. The body
+// either is a short status text, or when requested a JSON-encoded [Response].
+func respond(w http.ResponseWriter, req *http.Request, checks []Check) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	resp := Response{Status: "ok", Checks: checks}
+	status := http.StatusOK
+	for _, check := range checks {
+		if !check.OK {
+			resp.Status = "failed"
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	if req.URL.Query().Has("verbose") || strings.Contains(req.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(resp.Status + "\n"))
+}
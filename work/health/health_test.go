@@ -0,0 +1,166 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+	"github.com/thediveo/clippy/work"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("health and readiness endpoints", func() {
+
+	var group = plugger.Group[work.Do]()
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+		slog.SetDefault(slog.New(slog.NewTextHandler(gbytes.NewBuffer(), nil)))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("doesn't serve without an address", func() {
+		cmd := &cobra.Command{}
+		Expect(serve(context.Background(), cmd)).To(Succeed())
+		clippy.AddFlags(cmd)
+		Expect(serve(context.Background(), cmd)).To(Succeed())
+	})
+
+	It("reports when it cannot serve", func() {
+		cmd := &cobra.Command{}
+		clippy.AddFlags(cmd)
+		Expect(cmd.ParseFlags([]string{"--" + HealthAddrFlagName,
+			"unix:" + filepath.Join(GinkgoT().TempDir(), "nada", "health.sock")})).To(Succeed())
+		Expect(serve(context.Background(), cmd)).To(HaveOccurred())
+	})
+
+	It("serves health and readiness", func() {
+		healthy := make(chan struct{})
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			work.SetHealth(ctx, errors.New("no database connection"))
+			<-healthy
+			work.SetHealth(ctx, nil)
+			work.Ready(ctx)
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("db"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sockPath := filepath.Join(GinkgoT().TempDir(), "health.sock")
+		rootCmd := &cobra.Command{
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return work.DoAll(ctx, cmd)
+			},
+		}
+		clippy.AddFlags(rootCmd)
+		rootCmd.SetArgs([]string{"--" + HealthAddrFlagName, "unix:" + sockPath})
+		errch := make(chan error, 1)
+		go func() { errch <- rootCmd.Execute() }()
+
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", sockPath)
+				},
+			},
+		}
+		defer client.CloseIdleConnections()
+		request := func(method, path string, accept string) (int, string) {
+			GinkgoHelper()
+			req, err := http.NewRequest(method, "http://health"+path, nil)
+			Expect(err).NotTo(HaveOccurred())
+			if accept != "" {
+				req.Header.Set("Accept", accept)
+			}
+			resp, err := client.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { _ = resp.Body.Close() }()
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			return resp.StatusCode, string(data)
+		}
+		Eventually(func() error {
+			resp, err := client.Get("http://health" + HealthzPath)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			return err
+		}).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(Succeed())
+
+		status, body := request(http.MethodGet, HealthzPath, "")
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		Expect(body).To(Equal("failed\n"))
+
+		status, body = request(http.MethodGet, HealthzPath+"?verbose", "")
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		var resp Response
+		Expect(json.Unmarshal([]byte(body), &resp)).To(Succeed())
+		Expect(resp).To(Equal(Response{
+			Status: "failed",
+			Checks: []Check{
+				{Worker: "clippy/work/health", OK: true},
+				{Worker: "db", OK: false, Error: "no database connection"},
+			},
+		}))
+
+		status, body = request(http.MethodGet, ReadyzPath, "application/json")
+		Expect(status).To(Equal(http.StatusServiceUnavailable))
+		Expect(json.Unmarshal([]byte(body), &resp)).To(Succeed())
+		Expect(resp.Checks).To(ConsistOf(
+			Check{Worker: "clippy/work/health", OK: true},
+			Check{Worker: "db", OK: false, Error: "not ready"}))
+
+		status, _ = request(http.MethodPost, ReadyzPath, "")
+		Expect(status).To(Equal(http.StatusMethodNotAllowed))
+
+		close(healthy)
+		Eventually(func() int {
+			status, _ := request(http.MethodGet, ReadyzPath, "")
+			return status
+		}).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(Equal(http.StatusOK))
+		status, body = request(http.MethodGet, HealthzPath, "")
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("ok\n"))
+
+		cancel()
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+	})
+
+})
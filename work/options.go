@@ -0,0 +1,46 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Option fine-tunes how [DoAll] and [Run] handle the workers.
+type Option func(*options)
+
+type options struct {
+	shutdownTimeout time.Duration // zero waits indefinitely.
+	reload          bool          // SIGHUP calls reload callbacks; see Run.
+	crashOnPanic    bool          // don't recover from panics in workers.
+	phaseTimeout    time.Duration // zero waits indefinitely for each shutdown phase.
+}
+
+// newOptions returns the options for the specified command after applying the
+// specified options.
+func newOptions(cmd *cobra.Command, opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if ctx := cmd.Context(); ctx != nil {
+		if d, ok := ctx.Value(ctxShutdownTimeout).(time.Duration); ok {
+			o.shutdownTimeout = d
+		}
+	}
+	return o
+}
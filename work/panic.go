@@ -0,0 +1,64 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// PanicError is returned by workers that panicked, carrying the worker's name,
+// the value passed to panic, and the stack trace at the time of the panic.
+type PanicError struct {
+	Worker string // name of the worker that panicked.
+	Value  any    // value passed to panic.
+	Stack  []byte // stack trace of the panicking goroutine.
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error, otherwise nil.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// WithCrashOnPanic makes workers crash the process when panicking, instead of
+// recovering and converting the panic into a [PanicError].
+func WithCrashOnPanic() Option {
+	return func(o *options) {
+		o.crashOnPanic = true
+	}
+}
+
+// callRecovering calls the specified Do work function, recovering from a
+// panic and returning it as a [PanicError].
+func callRecovering(ctx context.Context, cmd *cobra.Command, fn Do) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = &PanicError{
+				Worker: Name(ctx),
+				Value:  value,
+				Stack:  debug.Stack(),
+			}
+		}
+	}()
+	return fn(ctx, cmd)
+}
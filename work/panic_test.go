@@ -0,0 +1,96 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("panicking workers", func() {
+
+	var output *gbytes.Buffer
+	var group = plugger.Group[Do]()
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+
+		output = gbytes.NewBuffer()
+		slog.SetDefault(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+		group.Clear()
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("recovers and winds down the other workers", func() {
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("good"))
+		group.Register(func(context.Context, *cobra.Command) error {
+			panic("boom!")
+		}, plugger.WithPlugin("boom"))
+
+		err := DoAll(context.Background(), &cobra.Command{})
+		Expect(err).To(MatchError(`worker "boom": panic: boom!`))
+		var perr *PanicError
+		Expect(errors.As(err, &perr)).To(BeTrue())
+		Expect(perr.Worker).To(Equal("boom"))
+		Expect(perr.Value).To(Equal("boom!"))
+		Expect(string(perr.Stack)).To(ContainSubstring("panic_test.go"))
+		Expect(errors.Unwrap(perr)).To(BeNil())
+		Expect(string(output.Contents())).To(MatchRegexp(
+			`level=ERROR msg="worker panicked" worker=boom duration=\S+ error="panic: boom!" stack=".*panic_test\.go`))
+	})
+
+	It("unwraps panicked errors", func() {
+		sentinel := errors.New("sentinel")
+		group.Register(func(context.Context, *cobra.Command) error {
+			panic(sentinel)
+		}, plugger.WithPlugin("boom"))
+
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(sentinel))
+	})
+
+	It("crashes when asked to", func() {
+		Expect(func() {
+			_ = do(context.Background(), &cobra.Command{}, "boom",
+				func(context.Context, *cobra.Command) error { panic("boom!") },
+				options{crashOnPanic: true})
+		}).To(PanicWith("boom!"))
+		var o options
+		WithCrashOnPanic()(&o)
+		Expect(o.crashOnPanic).To(BeTrue())
+	})
+
+})
@@ -0,0 +1,160 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thediveo/go-plugger/v3"
+)
+
+// Phase defines an exposed plugin symbol type for assigning the [Do] work
+// function exposed by the same (named) plugin to a [ShutdownPhase]. Without a
+// Phase symbol, a worker belongs to the [PhaseDrain] shutdown phase.
+type Phase func() ShutdownPhase
+
+// ShutdownPhase specifies when a worker gets cancelled when winding down.
+// [DoAll] cancels the workers phase by phase, waiting for the workers of a
+// phase to return (or the phase to time out) before cancelling the workers of
+// the next phase.
+type ShutdownPhase int
+
+// The supported shutdown phases, in order of shutting down.
+const (
+	PhaseIngress ShutdownPhase = iota // stop accepting new work, such as incoming requests.
+	PhaseDrain                        // finish in-flight work; the default phase.
+	PhaseBackend                      // close backends, such as database connections.
+
+	numPhases = iota
+)
+
+func (p ShutdownPhase) String() string {
+	switch p {
+	case PhaseIngress:
+		return "ingress"
+	case PhaseDrain:
+		return "drain"
+	case PhaseBackend:
+		return "backend"
+	}
+	return "ShutdownPhase(" + strconv.Itoa(int(p)) + ")"
+}
+
+// WithPhaseTimeout sets the maximum duration of each shutdown phase, after
+// which [DoAll] proceeds with cancelling the workers of the next phase, even if
+// not all workers of the current phase have returned yet. This includes
+// cancelling the workers needed by workers still running in the timed-out
+// phase. A zero duration, the default, waits indefinitely for the workers of
+// each phase to return.
+func WithPhaseTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.phaseTimeout = d
+	}
+}
+
+// assignPhases assigns the workers to their shutdown phases, as declared by
+// their Phase symbols. It returns an error if a phase is invalid, or if a
+// worker needs another worker from an earlier shutdown phase, which would
+// then be gone before the worker depending on it.
+func assignPhases(workers []*worker) error {
+	phaseGroup := plugger.Group[Phase]()
+	for _, w := range workers {
+		w.phase = PhaseDrain
+		if phase := phaseGroup.PluginSymbol(w.name); phase != nil {
+			w.phase = phase()
+		}
+		if w.phase < 0 || w.phase >= numPhases {
+			return fmt.Errorf("worker %q has invalid shutdown phase %d", w.name, int(w.phase))
+		}
+	}
+	for _, w := range workers {
+		for _, need := range w.needs {
+			if need.phase < w.phase {
+				return fmt.Errorf("worker %q in shutdown phase %s needs worker %q in earlier shutdown phase %s",
+					w.name, w.phase, need.name, need.phase)
+			}
+		}
+	}
+	return nil
+}
+
+// shutDownInPhases waits for the specified context to be done and then
+// signals the workers to shut down phase by phase, waiting for the workers of
+// each phase to return or the specified phase timeout (unless zero) to pass.
+// When a phase is over, workers still running in this phase don't hold up
+// cancelling the workers they need in later phases anymore.
+func shutDownInPhases(ctx context.Context, workers []*worker, timeout time.Duration) {
+	var starts, overs [numPhases]chan struct{}
+	var byPhase [numPhases][]*worker
+	for phase := range starts {
+		starts[phase] = make(chan struct{})
+		overs[phase] = make(chan struct{})
+	}
+	for _, w := range workers {
+		w.shutdown = starts[w.phase]
+		w.shutdownOver = overs[w.phase]
+		byPhase[w.phase] = append(byPhase[w.phase], w)
+	}
+	go func() {
+		<-ctx.Done()
+		for phase, workers := range byPhase {
+			close(starts[phase])
+			if len(workers) == 0 {
+				close(overs[phase])
+				continue
+			}
+			slog.Debug("shutdown phase starting", slog.String("phase", ShutdownPhase(phase).String()))
+			if pending := awaitWorkers(workers, timeout); len(pending) > 0 {
+				slog.Warn("shutdown phase timed out",
+					slog.String("phase", ShutdownPhase(phase).String()),
+					slog.String("pending", strings.Join(pending, ", ")))
+			}
+			close(overs[phase])
+		}
+	}()
+}
+
+// awaitWorkers waits for the specified workers to return, or the specified
+// timeout (unless zero) to pass, returning the quoted names of the workers
+// still running.
+func awaitWorkers(workers []*worker, timeout time.Duration) []string {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for idx, w := range workers {
+		select {
+		case <-w.done:
+		case <-expired:
+			var pending []string
+			for _, w := range workers[idx:] {
+				select {
+				case <-w.done:
+				default:
+					pending = append(pending, strconv.Quote(w.name))
+				}
+			}
+			return pending
+		}
+	}
+	return nil
+}
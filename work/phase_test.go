@@ -0,0 +1,162 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("shutdown phases", func() {
+
+	var output *gbytes.Buffer
+	var group = plugger.Group[Do]()
+	var phases = plugger.Group[Phase]()
+	var needs = plugger.Group[Needs]()
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+		output = gbytes.NewBuffer()
+		slog.SetDefault(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+		group.Clear()
+		oldPhases := phases.Backup()
+		DeferCleanup(func() { phases.Restore(oldPhases) })
+		phases.Clear()
+		oldNeeds := needs.Backup()
+		DeferCleanup(func() { needs.Restore(oldNeeds) })
+		needs.Clear()
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	// register registers the specified Do work function in the specified
+	// shutdown phase.
+	register := func(name string, phase ShutdownPhase, fn Do) {
+		group.Register(fn, plugger.WithPlugin(name))
+		phases.Register(func() ShutdownPhase { return phase }, plugger.WithPlugin(name))
+	}
+
+	It("names phases", func() {
+		Expect(PhaseIngress.String()).To(Equal("ingress"))
+		Expect(PhaseDrain.String()).To(Equal("drain"))
+		Expect(PhaseBackend.String()).To(Equal("backend"))
+		Expect(ShutdownPhase(42).String()).To(Equal("ShutdownPhase(42)"))
+	})
+
+	It("cancels workers phase by phase", func() {
+		var mu sync.Mutex
+		var cancelled []string
+		worker := func(ctx context.Context, _ *cobra.Command) error {
+			<-ctx.Done()
+			mu.Lock()
+			cancelled = append(cancelled, Name(ctx))
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}
+		register("a-backend", PhaseBackend, worker)
+		register("b-ingress", PhaseIngress, worker)
+		group.Register(worker, plugger.WithPlugin("c-drain"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		Expect(DoAll(ctx, &cobra.Command{})).To(Succeed())
+		Expect(cancelled).To(Equal([]string{"b-ingress", "c-drain", "a-backend"}))
+		Expect(string(output.Contents())).To(MatchRegexp(
+			`(?s)msg="shutdown phase starting" phase=ingress.*phase=drain.*phase=backend`))
+	})
+
+	It("proceeds with the next phase after a phase timed out", func() {
+		release := make(chan struct{})
+		register("stuck", PhaseIngress, func(context.Context, *cobra.Command) error {
+			<-release
+			return nil
+		})
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			<-ctx.Done()
+			close(release)
+			return nil
+		}, plugger.WithPlugin("drain"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(DoAll(ctx, &cobra.Command{}, WithPhaseTimeout(10*time.Millisecond))).To(Succeed())
+		Expect(string(output.Contents())).To(ContainSubstring(
+			`level=WARN msg="shutdown phase timed out" phase=ingress pending="\"stuck\""`))
+	})
+
+	It("cancels needed workers after the phase of their dependents timed out", func() {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		register("db", PhaseBackend, func(ctx context.Context, _ *cobra.Command) error {
+			Ready(ctx)
+			<-ctx.Done()
+			close(release)
+			return nil
+		})
+		group.Register(func(context.Context, *cobra.Command) error {
+			close(started)
+			<-release
+			return nil
+		}, plugger.WithPlugin("api"))
+		needs.Register(func() []string { return []string{"db"} }, plugger.WithPlugin("api"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-started
+			cancel()
+		}()
+		Expect(DoAll(ctx, &cobra.Command{},
+			WithPhaseTimeout(50*time.Millisecond), WithShutdownTimeout(time.Second))).To(Succeed())
+		Expect(string(output.Contents())).To(ContainSubstring(
+			`level=WARN msg="shutdown phase timed out" phase=drain pending="\"api\""`))
+	})
+
+	It("rejects invalid phases", func() {
+		register("foo", ShutdownPhase(-1), func(context.Context, *cobra.Command) error { return nil })
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(
+			`worker "foo" has invalid shutdown phase -1`))
+	})
+
+	It("rejects needing workers from earlier phases", func() {
+		register("mq", PhaseIngress, func(context.Context, *cobra.Command) error { return nil })
+		group.Register(func(context.Context, *cobra.Command) error { return nil }, plugger.WithPlugin("http"))
+		needs.Register(func() []string { return []string{"mq"} }, plugger.WithPlugin("http"))
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(
+			`worker "http" in shutdown phase drain needs worker "mq" in earlier shutdown phase ingress`))
+	})
+
+})
@@ -0,0 +1,145 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/thediveo/go-plugger/v3"
+)
+
+// Restart defines an exposed plugin symbol type for declaring the restart
+// policy of the [Do] work function exposed by the same (named) plugin. Without
+// a Restart symbol, a worker never gets restarted.
+type Restart func() RestartPolicy
+
+// RestartMode specifies when to restart a worker.
+type RestartMode int
+
+// The supported restart modes.
+const (
+	RestartNever     RestartMode = iota // never restart; any error fails the group.
+	RestartOnFailure                    // restart only after the worker returned an error.
+	RestartAlways                       // restart after the worker returned, with or without error.
+)
+
+// Default backoff durations of restart policies.
+const (
+	DefaultInitialBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// RestartPolicy specifies when and how often to restart a worker. The backoff
+// between restarts starts with InitialBackoff and then doubles with each
+// consecutive restart up to MaxBackoff. When a worker ran at least for
+// MaxBackoff before returning, the backoff resets to InitialBackoff.
+//
+// If a worker needs to be restarted more than MaxRestarts times within Window,
+// the restart policy escalates the worker's last error to the whole group,
+// failing it.
+type RestartPolicy struct {
+	Mode           RestartMode
+	InitialBackoff time.Duration // defaults to DefaultInitialBackoff.
+	MaxBackoff     time.Duration // defaults to DefaultMaxBackoff, but at least InitialBackoff.
+	MaxRestarts    int           // 0 is unlimited.
+	Window         time.Duration // 0 counts restarts over the worker's lifetime.
+}
+
+// restartPolicy returns the restart policy of the named worker, as exposed by
+// the Restart symbol of the same plugin, with defaults applied.
+func restartPolicy(name string) RestartPolicy {
+	var policy RestartPolicy
+	if restart := plugger.Group[Restart]().PluginSymbol(name); restart != nil {
+		policy = restart()
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = DefaultInitialBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = max(DefaultMaxBackoff, policy.InitialBackoff)
+	}
+	return policy
+}
+
+// supervise calls the specified attempt function, restarting it according to
+// the specified policy until it doesn't need to be restarted anymore, the
+// restart limit has been exceeded, or the context is done.
+func supervise(ctx context.Context, policy RestartPolicy, attempt func(context.Context) error) error {
+	log := Logger(ctx)
+	w := workerOf(ctx)
+	backoff := policy.InitialBackoff
+	var restarts []time.Time
+	for {
+		start := time.Now()
+		err := attempt(ctx)
+		if ctx.Err() != nil {
+			return err
+		}
+		switch policy.Mode {
+		case RestartOnFailure:
+			if err == nil {
+				return nil
+			}
+		case RestartAlways:
+		default:
+			return err
+		}
+
+		now := time.Now()
+		if policy.Window > 0 {
+			for len(restarts) > 0 && now.Sub(restarts[0]) > policy.Window {
+				restarts = restarts[1:]
+			}
+		}
+		if policy.MaxRestarts > 0 && len(restarts) >= policy.MaxRestarts {
+			limitErr := fmt.Errorf("restart limit of %d exceeded", policy.MaxRestarts)
+			if policy.Window > 0 {
+				limitErr = fmt.Errorf("restart limit of %d within %s exceeded", policy.MaxRestarts, policy.Window)
+			}
+			if err == nil {
+				return limitErr
+			}
+			return fmt.Errorf("%w: %w", limitErr, err)
+		}
+		restarts = append(restarts, now)
+		if w != nil {
+			w.unready()
+			w.status.restarted(err)
+		}
+
+		if now.Sub(start) >= policy.MaxBackoff {
+			backoff = policy.InitialBackoff
+		}
+		if err != nil {
+			log.Warn("worker restarting", slog.Duration("backoff", backoff), slog.Any("error", err))
+		} else {
+			log.Info("worker restarting", slog.Duration("backoff", backoff))
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		if w != nil {
+			w.status.setState(StateRunning)
+		}
+		backoff = min(2*backoff, policy.MaxBackoff)
+	}
+}
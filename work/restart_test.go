@@ -0,0 +1,176 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("restarting workers", func() {
+
+	var output *gbytes.Buffer
+	var group = plugger.Group[Do]()
+	var restarts = plugger.Group[Restart]()
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+
+		output = gbytes.NewBuffer()
+		slog.SetDefault(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+		group.Clear()
+		oldRestarts := restarts.Backup()
+		DeferCleanup(func() { restarts.Restore(oldRestarts) })
+		restarts.Clear()
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	// register registers the specified Do work function together with the
+	// specified restart policy.
+	register := func(name string, fn Do, policy RestartPolicy) {
+		group.Register(fn, plugger.WithPlugin(name))
+		restarts.Register(func() RestartPolicy { return policy }, plugger.WithPlugin(name))
+	}
+
+	It("applies defaults", func() {
+		Expect(restartPolicy("nada")).To(Equal(RestartPolicy{
+			Mode:           RestartNever,
+			InitialBackoff: DefaultInitialBackoff,
+			MaxBackoff:     DefaultMaxBackoff,
+		}))
+		restarts.Register(func() RestartPolicy {
+			return RestartPolicy{Mode: RestartAlways, InitialBackoff: time.Second}
+		}, plugger.WithPlugin("slow"))
+		Expect(restartPolicy("slow")).To(Equal(RestartPolicy{
+			Mode:           RestartAlways,
+			InitialBackoff: time.Second,
+			MaxBackoff:     DefaultMaxBackoff,
+		}))
+		restarts.Register(func() RestartPolicy {
+			return RestartPolicy{Mode: RestartAlways, InitialBackoff: time.Hour}
+		}, plugger.WithPlugin("slower"))
+		Expect(restartPolicy("slower")).To(Equal(RestartPolicy{
+			Mode:           RestartAlways,
+			InitialBackoff: time.Hour,
+			MaxBackoff:     time.Hour,
+		}))
+	})
+
+	It("restarts on failures, including panics", func() {
+		var attempts atomic.Int32
+		register("flaky", func(context.Context, *cobra.Command) error {
+			switch attempts.Add(1) {
+			case 1:
+				return errors.New("foo!")
+			case 2:
+				panic("boom!")
+			}
+			return nil
+		}, RestartPolicy{Mode: RestartOnFailure, InitialBackoff: time.Millisecond})
+
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(Succeed())
+		Expect(attempts.Load()).To(Equal(int32(3)))
+		Expect(string(output.Contents())).To(MatchRegexp(
+			`(?s)level=WARN msg="worker restarting" worker=flaky backoff=1ms error=foo!.*` +
+				`level=WARN msg="worker restarting" worker=flaky backoff=2ms error="panic: boom!"`))
+	})
+
+	It("doesn't restart on failure when never asked to", func() {
+		var attempts atomic.Int32
+		register("fragile", func(context.Context, *cobra.Command) error {
+			attempts.Add(1)
+			return errors.New("foo!")
+		}, RestartPolicy{})
+
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(`worker "fragile": foo!`))
+		Expect(attempts.Load()).To(Equal(int32(1)))
+	})
+
+	It("escalates after exceeding the restart limit", func() {
+		sentinel := errors.New("foo!")
+		var attempts atomic.Int32
+		register("failing", func(context.Context, *cobra.Command) error {
+			attempts.Add(1)
+			return sentinel
+		}, RestartPolicy{Mode: RestartOnFailure, InitialBackoff: time.Millisecond, MaxRestarts: 2})
+
+		err := DoAll(context.Background(), &cobra.Command{})
+		Expect(err).To(MatchError(sentinel))
+		Expect(err).To(MatchError(`worker "failing": restart limit of 2 exceeded: foo!`))
+		Expect(attempts.Load()).To(Equal(int32(3)))
+	})
+
+	It("always restarts, up to the limit within the window", func() {
+		var attempts atomic.Int32
+		register("oneshot", func(context.Context, *cobra.Command) error {
+			attempts.Add(1)
+			return nil
+		}, RestartPolicy{Mode: RestartAlways, InitialBackoff: time.Millisecond, MaxRestarts: 1, Window: time.Hour})
+
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(MatchError(
+			`worker "oneshot": restart limit of 1 within 1h0m0s exceeded`))
+		Expect(attempts.Load()).To(Equal(int32(2)))
+		Expect(string(output.Contents())).To(ContainSubstring(`level=INFO msg="worker restarting" worker=oneshot backoff=1ms`))
+	})
+
+	It("forgets restarts outside the window", func() {
+		var attempts atomic.Int32
+		register("flaky", func(ctx context.Context, _ *cobra.Command) error {
+			if attempts.Add(1) <= 3 {
+				time.Sleep(5 * time.Millisecond)
+				return errors.New("foo!")
+			}
+			return nil
+		}, RestartPolicy{Mode: RestartOnFailure, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond,
+			MaxRestarts: 1, Window: 2 * time.Millisecond})
+
+		Expect(DoAll(context.Background(), &cobra.Command{})).To(Succeed())
+		Expect(attempts.Load()).To(Equal(int32(4)))
+	})
+
+	It("stops restarting when the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		register("oneshot", func(context.Context, *cobra.Command) error {
+			cancel()
+			return nil
+		}, RestartPolicy{Mode: RestartAlways, InitialBackoff: time.Hour})
+		register("backingoff", func(context.Context, *cobra.Command) error {
+			return nil
+		}, RestartPolicy{Mode: RestartAlways, InitialBackoff: time.Hour})
+
+		Expect(DoAll(ctx, &cobra.Command{})).To(Succeed())
+	})
+
+})
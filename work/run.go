@@ -0,0 +1,147 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// ForcedExitCode is the exit code when [Run] force-exits the process upon
+// receiving a second SIGINT or SIGTERM while the workers are still winding
+// down after the first one.
+const ForcedExitCode = 3
+
+// osExit allows tests to intercept force-exiting.
+var osExit = os.Exit
+
+// shutdownSignals allows tests to use other signals than SIGINT and SIGTERM,
+// which are already taken by the test framework.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// WithReload makes [Run] call the reload callbacks registered using [OnReload]
+// upon receiving SIGHUP.
+func WithReload() Option {
+	return func(o *options) {
+		o.reload = true
+	}
+}
+
+// Run all registered (background) work Do plugin functions using [DoAll],
+// until either all work has ended or a SIGINT or SIGTERM arrives. The first
+// such signal cancels the context passed to the workers so that they wind
+// down gracefully, while a second such signal force-exits the process with
+// [ForcedExitCode]. Run derives the context passed to the workers from the
+// command's context, if any.
+//
+// When passed the [WithReload] option, Run calls the reload callbacks
+// registered by workers using [OnReload] upon receiving SIGHUP.
+func Run(cmd *cobra.Command, opts ...Option) error {
+	o := newOptions(cmd, opts)
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	signals := slices.Clone(shutdownSignals)
+	var reloads *reloaders
+	if o.reload {
+		signals = append(signals, syscall.SIGHUP)
+		reloads = &reloaders{callbacks: map[int]func(){}}
+		ctx = context.WithValue(ctx, ctxReloaders, reloads)
+	}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, signals...)
+	defer signal.Stop(sigs)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		shuttingDown := false
+		for {
+			select {
+			case sig := <-sigs:
+				switch {
+				case sig == syscall.SIGHUP:
+					slog.Info("reloading", slog.String("signal", sig.String()))
+					reloads.reload()
+				case shuttingDown:
+					slog.Error("forcing exit", slog.String("signal", sig.String()))
+					osExit(ForcedExitCode)
+				default:
+					slog.Info("shutting down", slog.String("signal", sig.String()))
+					shuttingDown = true
+					cancel()
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	return DoAll(ctx, cmd, opts...)
+}
+
+// reloaders keeps track of the reload callbacks registered by workers.
+type reloaders struct {
+	mu        sync.Mutex
+	next      int
+	callbacks map[int]func()
+}
+
+// reload calls all registered reload callbacks.
+func (r *reloaders) reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, callback := range r.callbacks {
+		callback()
+	}
+}
+
+// OnReload registers the specified callback to be called when [Run] receives
+// SIGHUP, if enabled using [WithReload]. The callback should return quickly,
+// such as by just signalling the worker. OnReload returns a function to
+// unregister the callback; if reloading isn't enabled for the specified
+// context, OnReload does nothing and returns a no-op function.
+func OnReload(ctx context.Context, callback func()) (unregister func()) {
+	r, ok := ctx.Value(ctxReloaders).(*reloaders)
+	if !ok {
+		return func() {}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.next
+	r.next++
+	r.callbacks[id] = callback
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.callbacks, id)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+//go:build unix
+
+package work
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("running work with signal handling", func() {
+
+	var output *gbytes.Buffer
+	var group = plugger.Group[Do]()
+	var started chan struct{}
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+
+		output = gbytes.NewBuffer()
+		slog.SetDefault(slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+		group.Clear()
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+
+		oldSignals := shutdownSignals
+		DeferCleanup(func() { shutdownSignals = oldSignals })
+		shutdownSignals = []os.Signal{syscall.SIGUSR1, syscall.SIGUSR2}
+
+		started = make(chan struct{})
+	})
+
+	// run runs the work in the background, returning a channel receiving the
+	// error returned by Run.
+	run := func(cmd *cobra.Command, opts ...Option) <-chan error {
+		errch := make(chan error, 1)
+		go func() { errch <- Run(cmd, opts...) }()
+		Eventually(started).Within(2 * time.Second).Should(BeClosed())
+		return errch
+	}
+
+	It("ends all work when all workers have returned", func() {
+		group.Register(func(context.Context, *cobra.Command) error { return nil })
+		Expect(Run(&cobra.Command{})).To(Succeed())
+	})
+
+	It("shuts down on a signal", func() {
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+		errch := run(&cobra.Command{})
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR2)).To(Succeed())
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+		Expect(string(output.Contents())).To(ContainSubstring(`msg="shutting down" signal="user defined signal 2"`))
+	})
+
+	It("force-exits on a second signal", func() {
+		oldExit := osExit
+		DeferCleanup(func() { osExit = oldExit })
+		exitCode := make(chan int, 1)
+		osExit = func(code int) { exitCode <- code }
+
+		release := make(chan struct{})
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			close(started)
+			<-release
+			return nil
+		})
+		errch := run(&cobra.Command{})
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR1)).To(Succeed())
+		Eventually(func() string { return string(output.Contents()) }).Within(2 * time.Second).
+			Should(ContainSubstring(`msg="shutting down" signal="user defined signal 1"`))
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR2)).To(Succeed())
+		Eventually(exitCode).Within(2 * time.Second).Should(Receive(Equal(ForcedExitCode)))
+		Expect(string(output.Contents())).To(ContainSubstring(`level=ERROR msg="forcing exit" signal="user defined signal 2"`))
+
+		close(release)
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+	})
+
+	It("reloads on SIGHUP", func() {
+		Expect(OnReload(context.Background(), func() {})).NotTo(BeNil())
+
+		reloaded := make(chan struct{}, 1)
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			unregister := OnReload(ctx, func() { reloaded <- struct{}{} })
+			defer unregister()
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+		errch := run(&cobra.Command{}, WithReload())
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGHUP)).To(Succeed())
+		Eventually(reloaded).Within(2 * time.Second).Should(Receive())
+		Expect(string(output.Contents())).To(ContainSubstring(`msg=reloading signal=hangup`))
+
+		Expect(syscall.Kill(os.Getpid(), syscall.SIGUSR2)).To(Succeed())
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+	})
+
+})
@@ -0,0 +1,25 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+/*
+Package shutdown supplies the “--shutdown-timeout” CLI flag for limiting the
+grace period of the workers run by [work.DoAll] to wind down after their
+context has been cancelled. When the grace period has been exceeded, DoAll
+returns with an error listing the workers still running, so that services
+terminate within a deadline, such as Kubernetes' termination grace period.
+
+The flag takes precedence over the grace period specified using
+[work.WithShutdownTimeout].
+*/
+package shutdown
@@ -0,0 +1,50 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package shutdown
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/clippy/work"
+	"github.com/thediveo/go-plugger/v3"
+)
+
+const (
+	ShutdownTimeoutFlagName = "shutdown-timeout"
+)
+
+func init() {
+	plugger.Group[cliplugin.SetupCLI]().Register(
+		setupCLI, plugger.WithPlugin("clippy/work/shutdown"))
+	plugger.Group[cliplugin.BeforeCommand]().Register(
+		beforeCommand, plugger.WithPlugin("clippy/work/shutdown"))
+}
+
+// setupCLI adds the "--shutdown-timeout" flag to the specified command.
+func setupCLI(cmd *cobra.Command) {
+	cmd.PersistentFlags().Duration(ShutdownTimeoutFlagName, 0,
+		"grace period for workers to wind down, such as \"10s\"; 0 waits indefinitely; "+
+			"if unspecified, the application's default applies")
+}
+
+// beforeCommand attaches the shutdown timeout to the command's context when the
+// "--shutdown-timeout" flag has been specified with the command.
+func beforeCommand(cmd *cobra.Command) error {
+	if flag := cmd.Flags().Lookup(ShutdownTimeoutFlagName); flag != nil && flag.Changed {
+		timeout, _ := cmd.Flags().GetDuration(ShutdownTimeoutFlagName)
+		work.SetShutdownTimeout(cmd, timeout)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package shutdown
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+	"github.com/thediveo/clippy/work"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("shutdown timeout flag", func() {
+
+	It("limits the shutdown grace period", func() {
+		group := plugger.Group[work.Do]()
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+		group.Clear()
+
+		release := make(chan struct{})
+		defer close(release)
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			<-release
+			return nil
+		}, plugger.WithPlugin("stuck"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rootCmd := &cobra.Command{
+			PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+				return clippy.BeforeCommand(cmd)
+			},
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return work.DoAll(ctx, cmd, work.WithShutdownTimeout(time.Hour))
+			},
+			SilenceErrors: true,
+			SilenceUsage:  true,
+		}
+		clippy.AddFlags(rootCmd)
+		rootCmd.SetArgs([]string{"--" + ShutdownTimeoutFlagName + "=10ms"})
+		Expect(rootCmd.Execute()).To(MatchError(
+			ContainSubstring(`shutdown timeout of 10ms exceeded, workers still running: "stuck"`)))
+	})
+
+})
@@ -0,0 +1,216 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State of a worker.
+type State string
+
+// The states of workers.
+const (
+	StateStarting   State = "starting"   // waiting for the workers it needs to become ready.
+	StateRunning    State = "running"    // working, but not signalled ready yet.
+	StateReady      State = "ready"      // working and signalled ready.
+	StateRestarting State = "restarting" // backing off before restarting.
+	StateStopping   State = "stopping"   // asked to wind down.
+	StateDone       State = "done"       // returned without error.
+	StateFailed     State = "failed"     // returned with an error.
+)
+
+// WorkerStatus is the status of a worker at a particular point in time.
+type WorkerStatus struct {
+	Name      string    `json:"name"`
+	State     State     `json:"state"`
+	Started   time.Time `json:"started,omitzero"`    // zero if not started yet.
+	Restarts  int       `json:"restarts"`            // number of restarts.
+	LastError string    `json:"lastError,omitempty"` // last error returned, if any.
+}
+
+// workerStatus is the mutable status of a worker.
+type workerStatus struct {
+	mu       sync.Mutex
+	state    State
+	started  time.Time
+	restarts int
+	lastErr  error
+	health   error // reported by the worker itself, if unhealthy.
+}
+
+// setState sets the state of the worker, unless it is already stopping or has
+// returned.
+func (s *workerStatus) setState(state State) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setStateLocked(state)
+}
+
+// setStateLocked sets the state of the worker, unless it is already stopping
+// or has returned. Must be called with the lock held.
+func (s *workerStatus) setStateLocked(state State) {
+	switch s.state {
+	case StateStopping, StateDone, StateFailed:
+		return
+	}
+	s.state = state
+}
+
+// start marks the worker as running, recording the start time.
+func (s *workerStatus) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = StateRunning
+	s.started = time.Now()
+}
+
+// restarted records a restart of the worker after the specified error, with the
+// worker backing off before restarting.
+func (s *workerStatus) restarted(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setStateLocked(StateRestarting)
+	s.health = nil
+	s.restarts++
+	if err != nil {
+		s.lastErr = err
+	}
+}
+
+// setHealth records the health reported by the worker.
+func (s *workerStatus) setHealth(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.health = err
+}
+
+// reportedHealth returns the health reported by the worker.
+func (s *workerStatus) reportedHealth() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.health
+}
+
+// finish marks the worker as done or failed, depending on the specified error.
+func (s *workerStatus) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.health = nil
+	if err != nil {
+		s.state = StateFailed
+		s.lastErr = err
+		return
+	}
+	s.state = StateDone
+}
+
+// statusRegistry keeps track of the workers of all [DoAll] calls still
+// running.
+var statusRegistry = struct {
+	mu   sync.Mutex
+	next int
+	runs map[int][]*worker
+}{runs: map[int][]*worker{}}
+
+// registerStatus registers the specified workers of a DoAll call, returning a
+// function to unregister them when DoAll returns.
+func registerStatus(workers []*worker) (unregister func()) {
+	statusRegistry.mu.Lock()
+	defer statusRegistry.mu.Unlock()
+	id := statusRegistry.next
+	statusRegistry.next++
+	statusRegistry.runs[id] = workers
+	return func() {
+		statusRegistry.mu.Lock()
+		defer statusRegistry.mu.Unlock()
+		delete(statusRegistry.runs, id)
+	}
+}
+
+// Status returns the status of the workers of all [DoAll] calls still running,
+// in the order of DoAll calls and then worker names.
+func Status() []WorkerStatus {
+	statusRegistry.mu.Lock()
+	defer statusRegistry.mu.Unlock()
+	var status []WorkerStatus
+	for _, id := range slices.Sorted(maps.Keys(statusRegistry.runs)) {
+		run := len(status)
+		for _, w := range statusRegistry.runs[id] {
+			status = append(status, w.status.snapshot(w.name))
+		}
+		slices.SortFunc(status[run:], func(a, b WorkerStatus) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+	return status
+}
+
+// SetHealth sets the health of the worker the specified context was passed to:
+// a nil error reports the worker as healthy, otherwise as unhealthy for the
+// reason given by the error. Workers start out healthy, and become healthy
+// again when restarted or returning. SetHealth does nothing if the context
+// wasn't passed to a Do work function by [DoAll].
+func SetHealth(ctx context.Context, err error) {
+	if w := workerOf(ctx); w != nil {
+		w.status.setHealth(err)
+	}
+}
+
+// Health returns the health of all unhealthy workers run by the same [DoAll]
+// as the worker the specified context was passed to, by worker names, as set
+// using [SetHealth]. Health returns nil if the context wasn't passed to a Do
+// work function by DoAll.
+func Health(ctx context.Context) map[string]error {
+	workers, ok := ctx.Value(ctxWorkers).([]*worker)
+	if !ok {
+		return nil
+	}
+	health := map[string]error{}
+	for _, w := range workers {
+		if err := w.status.reportedHealth(); err != nil {
+			health[w.name] = errors.Join(health[w.name], err)
+		}
+	}
+	return health
+}
+
+// snapshot returns the current status of the named worker.
+func (s *workerStatus) snapshot(name string) WorkerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := WorkerStatus{
+		Name:     name,
+		State:    s.state,
+		Started:  s.started,
+		Restarts: s.restarts,
+	}
+	if s.lastErr != nil {
+		status.LastError = s.lastErr.Error()
+	}
+	return status
+}
+
+// workerOf returns the worker the specified context was passed to, or nil.
+func workerOf(ctx context.Context) *worker {
+	w, _ := ctx.Value(ctxWorker).(*worker)
+	return w
+}
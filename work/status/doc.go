@@ -0,0 +1,27 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+/*
+Package status supplies a [work.Do] plugin serving the status of the workers
+run by [work.DoAll] as JSON on a “/status” HTTP debug endpoint, at the address
+specified using the “--status-addr” CLI flag. The address is either a TCP
+host:port address, or a unix socket path prefixed with “unix:”. Without this
+flag, no endpoint is served.
+
+The endpoint returns the [work.WorkerStatus] list returned by [work.Status].
+
+The status worker belongs to the [work.PhaseBackend] shutdown phase, so that it
+keeps serving while other workers are winding down.
+*/
+package status
@@ -0,0 +1,84 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy/cliplugin"
+	"github.com/thediveo/clippy/internal/endpoint"
+	"github.com/thediveo/clippy/work"
+	"github.com/thediveo/go-plugger/v3"
+)
+
+const (
+	StatusAddrFlagName = "status-addr"
+)
+
+// StatusPath is the path of the status endpoint.
+const StatusPath = "/status"
+
+func init() {
+	plugger.Group[cliplugin.SetupCLI]().Register(
+		setupCLI, plugger.WithPlugin("clippy/work/status"))
+	plugger.Group[work.Do]().Register(
+		serve, plugger.WithPlugin("clippy/work/status"))
+	plugger.Group[work.Phase]().Register(
+		func() work.ShutdownPhase { return work.PhaseBackend }, plugger.WithPlugin("clippy/work/status"))
+}
+
+// setupCLI adds the "--status-addr" flag to the specified command.
+func setupCLI(cmd *cobra.Command) {
+	cmd.PersistentFlags().String(StatusAddrFlagName, "",
+		"serves the status of workers as JSON on an HTTP endpoint "+StatusPath+
+			" at the specified host:port or unix:path")
+}
+
+// serve the status endpoint at the address specified by the "--status-addr"
+// flag until the context is done. If no address was specified, serve
+// immediately returns.
+func serve(ctx context.Context, cmd *cobra.Command) error {
+	addr, _ := cmd.Flags().GetString(StatusAddrFlagName)
+	if addr == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(StatusPath, func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		status := work.Status()
+		if status == nil {
+			status = []work.WorkerStatus{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(status)
+	})
+	stop, err := endpoint.Serve(addr, mux)
+	if err != nil {
+		return err
+	}
+	work.Ready(ctx)
+	<-ctx.Done()
+	return stop()
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/clippy"
+	"github.com/thediveo/clippy/work"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("status endpoint", func() {
+
+	var group = plugger.Group[work.Do]()
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+		slog.SetDefault(slog.New(slog.NewTextHandler(gbytes.NewBuffer(), nil)))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("doesn't serve without an address", func() {
+		cmd := &cobra.Command{}
+		clippy.AddFlags(cmd)
+		Expect(serve(context.Background(), cmd)).To(Succeed())
+	})
+
+	It("reports when it cannot serve", func() {
+		cmd := &cobra.Command{}
+		clippy.AddFlags(cmd)
+		Expect(cmd.ParseFlags([]string{"--" + StatusAddrFlagName,
+			"unix:" + filepath.Join(GinkgoT().TempDir(), "nada", "status.sock")})).To(Succeed())
+		Expect(serve(context.Background(), cmd)).To(HaveOccurred())
+	})
+
+	It("serves the status of workers", func() {
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			work.Ready(ctx)
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("foo"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sockPath := filepath.Join(GinkgoT().TempDir(), "status.sock")
+		rootCmd := &cobra.Command{
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				return work.DoAll(ctx, cmd)
+			},
+		}
+		clippy.AddFlags(rootCmd)
+		rootCmd.SetArgs([]string{"--" + StatusAddrFlagName, "unix:" + sockPath})
+		errch := make(chan error, 1)
+		go func() { errch <- rootCmd.Execute() }()
+
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", sockPath)
+				},
+			},
+		}
+		defer client.CloseIdleConnections()
+		request := func(method string) (int, string) {
+			GinkgoHelper()
+			req, err := http.NewRequest(method, "http://status"+StatusPath, nil)
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := client.Do(req)
+			if err != nil {
+				return 0, ""
+			}
+			defer func() { _ = resp.Body.Close() }()
+			data, err := io.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			return resp.StatusCode, string(data)
+		}
+
+		var status []work.WorkerStatus
+		Eventually(func() []work.WorkerStatus {
+			code, body := request(http.MethodGet)
+			if code != http.StatusOK {
+				return nil
+			}
+			Expect(json.Unmarshal([]byte(body), &status)).To(Succeed())
+			return status
+		}).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(ConsistOf(
+			HaveField("Name", "clippy/work/status"),
+			And(HaveField("Name", "foo"), HaveField("State", work.StateReady), HaveField("Started", Not(BeZero()))),
+		))
+
+		code, _ := request(http.MethodPost)
+		Expect(code).To(Equal(http.StatusMethodNotAllowed))
+
+		cancel()
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+	})
+
+})
@@ -0,0 +1,231 @@
+// Copyright 2025 Harald Albrecht.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"); you may not
+// use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+// License for the specific language governing permissions and limitations
+// under the License.
+
+package work
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/thediveo/go-plugger/v3"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gleak"
+)
+
+var _ = Describe("worker status", func() {
+
+	var group = plugger.Group[Do]()
+	var needs = plugger.Group[Needs]()
+	var restarts = plugger.Group[Restart]()
+
+	BeforeEach(func() {
+		old := slog.Default()
+		DeferCleanup(func() { slog.SetDefault(old) })
+		slog.SetDefault(slog.New(slog.NewTextHandler(gbytes.NewBuffer(), nil)))
+
+		oldDoers := group.Backup()
+		DeferCleanup(func() { group.Restore(oldDoers) })
+		group.Clear()
+		oldNeeds := needs.Backup()
+		DeferCleanup(func() { needs.Restore(oldNeeds) })
+		needs.Clear()
+		oldRestarts := restarts.Backup()
+		DeferCleanup(func() { restarts.Restore(oldRestarts) })
+		restarts.Clear()
+
+		goodgos := Goroutines()
+		DeferCleanup(func() {
+			Eventually(Goroutines).Within(2 * time.Second).ProbeEvery(100 * time.Millisecond).
+				ShouldNot(HaveLeaked(goodgos))
+		})
+	})
+
+	It("has no status outside DoAll", func() {
+		Expect(Status()).To(BeEmpty())
+	})
+
+	It("tracks the status of workers", func() {
+		var attempts atomic.Int32
+		ready := make(chan struct{})
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			if attempts.Add(1) == 1 {
+				return errors.New("foo!")
+			}
+			<-ready
+			Ready(ctx)
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("db"))
+		restarts.Register(func() RestartPolicy {
+			return RestartPolicy{Mode: RestartOnFailure, InitialBackoff: time.Millisecond}
+		}, plugger.WithPlugin("db"))
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			return errors.New("bar!")
+		}, plugger.WithPlugin("http"))
+		needs.Register(func() []string { return []string{"db"} }, plugger.WithPlugin("http"))
+		group.Register(func(context.Context, *cobra.Command) error {
+			return nil
+		}, plugger.WithPlugin("oneshot"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errch := make(chan error, 1)
+		go func() { errch <- DoAll(ctx, &cobra.Command{}) }()
+
+		Eventually(Status).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(ConsistOf(
+			And(HaveField("Name", "db"), HaveField("State", StateRunning),
+				HaveField("Restarts", 1), HaveField("LastError", "foo!"), HaveField("Started", Not(BeZero()))),
+			And(HaveField("Name", "http"), HaveField("State", StateStarting), HaveField("Started", BeZero())),
+			And(HaveField("Name", "oneshot"), HaveField("State", StateDone)),
+		))
+
+		close(ready)
+		Eventually(Status).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(ContainElements(
+			And(HaveField("Name", "db"), HaveField("State", StateReady)),
+			And(HaveField("Name", "http"), HaveField("State", StateRunning)),
+		))
+
+		cancel()
+		Eventually(Status).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(ContainElements(
+			And(HaveField("Name", "db"), HaveField("State", StateReady)),
+			And(HaveField("Name", "http"), HaveField("State", StateStopping)),
+		))
+		Eventually(errch).Within(2 * time.Second).Should(Receive(MatchError(`worker "http": bar!`)))
+		Expect(Status()).To(BeEmpty())
+	})
+
+	It("sorts the status of workers by name", func() {
+		worker := func(ctx context.Context, _ *cobra.Command) error {
+			<-ctx.Done()
+			return nil
+		}
+		group.Register(worker, plugger.WithPlugin("a"))
+		group.Register(worker, plugger.WithPlugin("b"))
+		group.Register(worker, plugger.WithPlugin("c"), plugger.WithPlacement("<a"))
+		Expect(group.Plugins()).To(Equal([]string{"c", "a", "b"}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errch := make(chan error, 1)
+		go func() { errch <- DoAll(ctx, &cobra.Command{}) }()
+
+		Eventually(func() []string {
+			var names []string
+			for _, status := range Status() {
+				names = append(names, status.Name)
+			}
+			return names
+		}).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(Equal([]string{"a", "b", "c"}))
+		cancel()
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+	})
+
+	It("tracks ready workers backing off before restarting", func() {
+		var attempts atomic.Int32
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			Ready(ctx)
+			if attempts.Add(1) == 1 {
+				return errors.New("db connection lost")
+			}
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("db"))
+		restarts.Register(func() RestartPolicy {
+			return RestartPolicy{Mode: RestartOnFailure, InitialBackoff: 200 * time.Millisecond}
+		}, plugger.WithPlugin("db"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errch := make(chan error, 1)
+		go func() { errch <- DoAll(ctx, &cobra.Command{}) }()
+
+		Eventually(Status).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(ConsistOf(
+			And(HaveField("State", StateRestarting), HaveField("Restarts", 1),
+				HaveField("LastError", "db connection lost"))))
+		Eventually(Status).Within(2 * time.Second).ProbeEvery(10 * time.Millisecond).Should(ConsistOf(
+			And(HaveField("State", StateReady), HaveField("Restarts", 1))))
+		cancel()
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+	})
+
+	It("ignores health outside workers", func() {
+		Expect(func() { SetHealth(context.Background(), errors.New("foo!")) }).NotTo(Panic())
+		Expect(Health(context.Background())).To(BeNil())
+	})
+
+	It("keeps the health of workers until they restart or return", func() {
+		var attempts atomic.Int32
+		probes := make(chan context.Context, 1)
+		fail := make(chan struct{})
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			if attempts.Add(1) == 1 {
+				SetHealth(ctx, errors.New("foo!"))
+				SetHealth(ctx, nil)
+				SetHealth(ctx, errors.New("bar!"))
+				probes <- ctx
+				<-fail
+				return errors.New("baz!")
+			}
+			<-ctx.Done()
+			return nil
+		}, plugger.WithPlugin("db"))
+		restarts.Register(func() RestartPolicy {
+			return RestartPolicy{Mode: RestartOnFailure, InitialBackoff: time.Millisecond}
+		}, plugger.WithPlugin("db"))
+		group.Register(func(ctx context.Context, _ *cobra.Command) error {
+			SetHealth(ctx, errors.New("oneshot!"))
+			return nil
+		}, plugger.WithPlugin("oneshot"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errch := make(chan error, 1)
+		go func() { errch <- DoAll(ctx, &cobra.Command{}) }()
+
+		var probe context.Context
+		Eventually(probes).Within(2 * time.Second).Should(Receive(&probe))
+		Eventually(func() map[string]error { return Health(probe) }).Within(2 * time.Second).
+			ProbeEvery(10 * time.Millisecond).Should(And(
+			HaveLen(1), HaveKeyWithValue("db", MatchError("bar!"))))
+		close(fail)
+		Eventually(func() int32 { return attempts.Load() }).Within(2 * time.Second).
+			ProbeEvery(10 * time.Millisecond).Should(Equal(int32(2)))
+		Expect(Health(probe)).To(BeEmpty())
+		cancel()
+		Eventually(errch).Within(2 * time.Second).Should(Receive(BeNil()))
+	})
+
+	It("doesn't change the state of workers that are stopping or have returned", func() {
+		var s workerStatus
+		s.start()
+		s.setState(StateStopping)
+		s.setState(StateReady)
+		s.restarted(nil)
+		Expect(s.snapshot("foo").State).To(Equal(StateStopping))
+		s.finish(errors.New("foo!"))
+		s.setState(StateStopping)
+		Expect(s.snapshot("foo")).To(And(
+			HaveField("State", StateFailed), HaveField("LastError", "foo!")))
+	})
+
+})
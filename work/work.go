@@ -16,7 +16,11 @@ package work
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"runtime/pprof"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/thediveo/go-plugger/v3"
@@ -31,12 +35,68 @@ import (
 // of being correctly wound down.
 type Do func(context.Context, *cobra.Command) error
 
+// WorkerKey is the key of the logging attribute carrying the name of a worker,
+// see also [Logger].
+const WorkerKey = "worker"
+
+// ctxKey "namespaces" the context keys this package uses internally for passing
+// information to the Do work functions.
+type ctxKey int
+
+const (
+	ctxName ctxKey = iota
+	ctxShutdownTimeout
+	ctxReloaders
+	ctxWorker
+	ctxWorkers
+)
+
+// Name returns the name of the worker the specified context was passed to,
+// that is, the plugin name of its Do function. Name returns an empty string if
+// the context wasn't passed to a Do work function by [DoAll].
+func Name(ctx context.Context) string {
+	name, _ := ctx.Value(ctxName).(string)
+	return name
+}
+
+// Logger returns the default logger with a "worker" attribute set to the name
+// of the worker the specified context was passed to. If the context wasn't
+// passed to a Do work function by [DoAll], Logger returns the default logger.
+func Logger(ctx context.Context) *slog.Logger {
+	if name := Name(ctx); name != "" {
+		return slog.Default().With(WorkerKey, name)
+	}
+	return slog.Default()
+}
+
 // DoAll starts all registered (background) work Do plugin functions and then
 // waits for them to return. If any work function returns an error, the context
 // passed to all Do work functions will be cancelled and the work functions
 // should then return, but without error. Do then either returns the earliest
-// reported error or nil in case all Do functions gracefully ended.
-func DoAll(ctx context.Context, cmd *cobra.Command) error {
+// reported error, wrapped with the name of the failed worker, or nil in case
+// all Do functions gracefully ended.
+//
+// The context passed to each Do work function carries the worker's name, which
+// is the plugin name of the Do function; see [Name] and [Logger]. DoAll logs
+// when each worker starts and ends, including the duration of its work and the
+// error it returned, if any.
+//
+// Workers declaring dependencies on other workers using [Needs] symbols start
+// only after the workers they need have signalled being ready using [Ready].
+// When winding down, a worker's context gets cancelled only after all workers
+// depending on it have returned. DoAll returns an error without starting any
+// worker if the dependencies are unknown or cyclic.
+//
+// Workers assigned to shutdown phases using [Phase] symbols get cancelled
+// phase by phase: first the [PhaseIngress] workers, then the [PhaseDrain]
+// workers, and finally the [PhaseBackend] workers. Each phase ends when all its
+// workers have returned or the phase timeout set by [WithPhaseTimeout] has
+// passed.
+//
+// Options, such as [WithShutdownTimeout], fine-tune how DoAll handles the
+// workers.
+func DoAll(ctx context.Context, cmd *cobra.Command, opts ...Option) error {
+	o := newOptions(cmd, opts)
 	name := cmd.DisplayName()
 	defer slog.Info(name + " work ended")
 
@@ -46,10 +106,74 @@ func DoAll(ctx context.Context, cmd *cobra.Command) error {
 	// gracefully wind down their work. Cancelling the passed-in context is not
 	// considered an error but the way of life, so Do worker functions should
 	// never return any non-nil error under these circumstances.
+	workers, err := newWorkers(plugger.Group[Do]().PluginsSymbols())
+	if err != nil {
+		return err
+	}
+	defer registerStatus(workers)()
+	ctx = context.WithValue(ctx, ctxWorkers, workers)
 	group, ctx := errgroup.WithContext(ctx)
 	slog.Info(name + " work starting")
-	for _, plug := range plugger.Group[Do]().PluginsSymbols() {
-		group.Go(func() error { return plug.S(ctx, cmd) })
+	running := newRunning()
+	shutDownInPhases(ctx, workers, o.phaseTimeout)
+	for idx, w := range workers {
+		running.start(idx, w.name)
+		// A worker's context gets cancelled only after its shutdown phase has
+		// started and all workers depending on it have returned, so workers
+		// shut down phase by phase and in reverse dependency order.
+		workerCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+		workerCtx = context.WithValue(workerCtx, ctxWorker, w)
+		go stopAfterDependents(ctx, w, stop)
+		group.Go(func() error {
+			defer close(w.done)
+			if !w.awaitNeeds(ctx) {
+				w.status.finish(nil)
+				running.done(idx, nil)
+				return nil
+			}
+			w.status.start()
+			err := do(workerCtx, cmd, w.name, w.fn, o)
+			if err == nil {
+				w.markReady()
+			}
+			w.status.finish(err)
+			running.done(idx, err)
+			return err
+		})
+	}
+	return wait(ctx, group, running, o.shutdownTimeout)
+}
+
+// do runs the specified Do work function with the worker's name attached to
+// its context, logging the start and end of its work. Unless told otherwise
+// by the options, do recovers from panics, returning them as errors. do
+// restarts the work function according to its restart policy, if any.
+func do(ctx context.Context, cmd *cobra.Command, name string, fn Do, o options) error {
+	ctx = context.WithValue(ctx, ctxName, name)
+	log := Logger(ctx)
+	log.Info("worker starting")
+	start := time.Now()
+	var err error
+	// Label the worker's goroutine(s) so we can later identify their stacks
+	// in case they don't wind down in time.
+	pprof.Do(ctx, pprof.Labels(WorkerKey, name), func(ctx context.Context) {
+		err = supervise(ctx, restartPolicy(name), func(ctx context.Context) error {
+			if o.crashOnPanic {
+				return fn(ctx, cmd)
+			}
+			return callRecovering(ctx, cmd, fn)
+		})
+	})
+	duration := time.Since(start)
+	if perr := (*PanicError)(nil); errors.As(err, &perr) {
+		log.Error("worker panicked", slog.Duration("duration", duration), slog.Any("error", err),
+			slog.String("stack", string(perr.Stack)))
+		return fmt.Errorf("worker %q: %w", name, err)
+	}
+	if err != nil {
+		log.Error("worker failed", slog.Duration("duration", duration), slog.Any("error", err))
+		return fmt.Errorf("worker %q: %w", name, err)
 	}
-	return group.Wait()
+	log.Info("worker ended", slog.Duration("duration", duration))
+	return nil
 }
@@ -118,4 +118,40 @@ var _ = Describe("service and background workers", func() {
 			Within(10 * time.Second).Should(MatchError(ContainSubstring("foo!")))
 	})
 
+	It("names workers", func() {
+		cmd := &cobra.Command{
+			Use: "foo does bar",
+		}
+
+		Expect(Name(context.Background())).To(BeEmpty())
+		Expect(Logger(context.Background())).To(BeIdenticalTo(slog.Default()))
+
+		group.Register(func(ctx context.Context, c *cobra.Command) error {
+			Logger(ctx).Info("*working*")
+			if Name(ctx) != "bar" {
+				return errors.New("wrong name " + Name(ctx))
+			}
+			return nil
+		}, plugger.WithPlugin("bar"))
+
+		Expect(DoAll(context.Background(), cmd)).To(Succeed())
+		Expect(output.String()).To(MatchRegexp(
+			`(?s)msg="worker starting" worker=bar.*msg=\*working\* worker=bar.*msg="worker ended" worker=bar duration=`))
+	})
+
+	It("names the failed worker", func() {
+		cmd := &cobra.Command{
+			Use: "foo does bar",
+		}
+
+		group.Register(func(ctx context.Context, c *cobra.Command) error {
+			return errors.New("foo!")
+		}, plugger.WithPlugin("baz"))
+
+		err := DoAll(context.Background(), cmd)
+		Expect(err).To(MatchError(`worker "baz": foo!`))
+		Expect(output.String()).To(MatchRegexp(
+			`level=ERROR msg="worker failed" worker=baz duration=\S+ error=foo!`))
+	})
+
 })